@@ -1,14 +1,25 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 
 	"net/http"
 	"os"
+	"os/signal"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/whyeasy/gitlab-extra-exporter/internal"
@@ -23,9 +34,77 @@ var (
 func init() {
 	flag.StringVar(&config.ListenAddress, "listenAddress", os.Getenv("LISTEN_ADDRESS"), "Port address of exporter to run on")
 	flag.StringVar(&config.ListenPath, "listenPath", os.Getenv("LISTEN_PATH"), "Path where metrics will be exposed")
+	flag.StringVar(&config.PublicListenPath, "publicListenPath", os.Getenv("PUBLIC_LISTEN_PATH"), "Path where metrics without merge request titles and usernames will be exposed as well, e.g. /metrics-public.")
+	flag.StringVar(&config.PushgatewayURL, "pushgatewayURL", os.Getenv("PUSHGATEWAY_URL"), "URL of a Pushgateway to push the metrics to after every background scrape.")
+	flag.StringVar(&config.PushJob, "pushJob", os.Getenv("PUSH_JOB"), "Job name used when pushing the metrics to the Pushgateway.")
+	flag.StringVar(&config.PathPrefix, "pathPrefix", os.Getenv("PATH_PREFIX"), "Path prefix of all endpoints, e.g. when hosting behind a reverse proxy at a subpath.")
 	flag.StringVar(&config.GitlabURI, "gitlabURI", os.Getenv("GITLAB_URI"), "URI to Gitlab instance to monitor")
 	flag.StringVar(&config.GitlabAPIKey, "gitlabAPIKey", os.Getenv("GITLAB_API_KEY"), "API Key to access the Gitlab instance")
-	flag.StringVar(&config.Interval, "interval", os.Getenv("INTERVAL"), "Provide a interval on what rate the Jira Service Desk API should be scraped.")
+	flag.StringVar(&config.Interval, "interval", os.Getenv("INTERVAL"), "Interval in seconds on which the projects and merge requests are retrieved from Gitlab.")
+	flag.StringVar(&config.DetailInterval, "detailInterval", os.Getenv("DETAIL_INTERVAL"), "Interval in seconds on which the expensive details, e.g. approvals, changes and pipelines, are retrieved.")
+	flag.StringVar(&config.SudoUser, "sudoUser", os.Getenv("SUDO_USER"), "Username or ID of the user to do the Gitlab requests as, requires an admin token.")
+	flag.StringVar(&config.ClientRetries, "clientRetries", os.Getenv("CLIENT_RETRIES"), "Amount of retries to connect to Gitlab at startup, e.g. when DNS isn't resolvable yet.")
+	flag.StringVar(&config.DrainPeriod, "drainPeriod", os.Getenv("DRAIN_PERIOD"), "Maximum amount of seconds to let active requests finish when shutting down.")
+	flag.StringVar(&config.ClientCertFile, "clientCertFile", os.Getenv("CLIENT_CERT_FILE"), "Client certificate file to authenticate to Gitlab with.")
+	flag.StringVar(&config.ClientKeyFile, "clientKeyFile", os.Getenv("CLIENT_KEY_FILE"), "Key file of the client certificate to authenticate to Gitlab with.")
+	flag.StringVar(&config.CollectTimeout, "collectTimeout", os.Getenv("COLLECT_TIMEOUT"), "Maximum amount of seconds to spend on collecting metrics for a single Prometheus scrape.")
+	flag.StringVar(&config.MaxSeries, "maxSeries", os.Getenv("MAX_SERIES"), "Maximum amount of series per merge request, above which those metrics are left out.")
+	flag.StringVar(&config.MaxDetailFetches, "maxDetailFetches", os.Getenv("MAX_DETAIL_FETCHES"), "Maximum amount of merge requests of which the details are retrieved per scrape.")
+	flag.StringVar(&config.ChangesRetryDelay, "changesRetryDelay", os.Getenv("CHANGES_RETRY_DELAY"), "Seconds to wait before retrieving an open merge request again of which Gitlab is still computing the changes.")
+	flag.StringVar(&config.GlobalConcurrency, "globalConcurrency", os.Getenv("GLOBAL_CONCURRENCY"), "Maximum amount of concurrent requests to Gitlab.")
+	flag.StringVar(&config.RequestDelay, "requestDelay", os.Getenv("REQUEST_DELAY"), "Duration to wait before every request to Gitlab, per worker.")
+	flag.StringVar(&config.Milestone, "milestone", os.Getenv("MILESTONE"), "Only retrieve merge requests of the given milestone.")
+	flag.StringVar(&config.TargetBranch, "targetBranch", os.Getenv("TARGET_BRANCH"), "Only retrieve merge requests targeting the given branch, e.g. master. Empty retrieves all target branches.")
+	flag.StringVar(&config.MRScope, "mrScope", os.Getenv("MR_SCOPE"), "Scope of the listed merge requests: all, created_by_me or assigned_to_me.")
+	flag.StringVar(&config.WindowBy, "windowBy", os.Getenv("WINDOW_BY"), "Select the merge requests of the last 7 days by updated_at or created_at.")
+	flag.StringVar(&config.MROrderBy, "mrOrderBy", os.Getenv("MR_ORDER_BY"), "Order the listed merge requests by created_at or updated_at.")
+	flag.StringVar(&config.MRSort, "mrSort", os.Getenv("MR_SORT"), "Sort the listed merge requests asc or desc.")
+	flag.StringVar(&config.MinProjectActivity, "minProjectActivity", os.Getenv("MIN_PROJECT_ACTIVITY"), "Only retrieve projects with activity within this duration, and their merge requests, e.g. 8760h.")
+	flag.BoolVar(&config.Membership, "membership", os.Getenv("MEMBERSHIP") == "true", "Only retrieve the projects the user of the token is a member of, and their merge requests.")
+	flag.StringVar(&config.PinnedProjects, "pinnedProjects", os.Getenv("PINNED_PROJECTS"), "Comma separated list of project IDs or paths that are always exported, even when they aren't listed.")
+	flag.StringVar(&config.ProjectIntervals, "projectIntervals", os.Getenv("PROJECT_INTERVALS"), "Comma separated list of project paths with an interval in seconds to refresh their merge requests on, e.g. group/project=15.")
+	flag.BoolVar(&config.IncludeForks, "includeForks", os.Getenv("INCLUDE_FORKS") == "true", "Retrieve the changes of merge requests of which the source branch lives in a fork.")
+	flag.BoolVar(&config.OnlyUnapproved, "onlyUnapproved", os.Getenv("ONLY_UNAPPROVED") == "true", "Leave the fully approved open merge requests out of all metrics.")
+	flag.StringVar(&config.TrackedLabels, "trackedLabels", os.Getenv("TRACKED_LABELS"), "Comma separated list of labels of which additions to open merge requests are counted.")
+	flag.StringVar(&config.PathFilter, "pathFilter", os.Getenv("PATH_FILTER"), "Comma separated list of glob patterns of file paths, only merge requests changing a matching file are kept, e.g. services/payments.")
+	flag.StringVar(&config.ExcludeTargetBranches, "excludeTargetBranches", os.Getenv("EXCLUDE_TARGET_BRANCHES"), "Comma separated list of glob patterns of target branches of which the merge requests are left out, e.g. sandbox/*.")
+	flag.StringVar(&config.ChangeExtensions, "changeExtensions", os.Getenv("CHANGE_EXTENSIONS"), "Comma separated list of file extensions of which the changes within open merge requests are counted separately.")
+	flag.StringVar(&config.Retention, "retention", os.Getenv("RETENTION"), "Duration to keep exporting merged and closed merge requests after they fall outside of the 7 day window.")
+	flag.StringVar(&config.MaxTitleLength, "maxTitleLength", os.Getenv("MAX_TITLE_LENGTH"), "Maximum length of the merge request title label, longer titles are truncated.")
+	flag.StringVar(&config.TitleRedactPattern, "titleRedactPattern", os.Getenv("TITLE_REDACT_PATTERN"), "Regular expression of merge request titles that are replaced with a placeholder in the title label.")
+	flag.BoolVar(&config.DropTitleLabel, "dropTitleLabel", os.Getenv("DROP_TITLE_LABEL") == "true", "Omit the merge request title label from the merge request info metric.")
+	flag.BoolVar(&config.DropInternalIDLabel, "dropInternalIDLabel", os.Getenv("DROP_INTERNAL_ID_LABEL") == "true", "Omit the merge request internal ID label from the merge request info metric.")
+	flag.StringVar(&config.LatencyThresholds, "latencyThresholds", os.Getenv("LATENCY_THRESHOLDS"), "Two ascending durations of the 95th percentile API latency, below the first Gitlab is fast and from the second it is slow.")
+	flag.StringVar(&config.BusinessHours, "businessHours", os.Getenv("BUSINESS_HOURS"), "Working hours like 09:00-17:00 to compute the business duration of merged merge requests with.")
+	flag.StringVar(&config.BusinessDays, "businessDays", os.Getenv("BUSINESS_DAYS"), "Comma separated list of working days for the business duration.")
+	flag.StringVar(&config.BusinessTimezone, "businessTimezone", os.Getenv("BUSINESS_TIMEZONE"), "Timezone of the working hours for the business duration, e.g. Europe/Amsterdam. Defaults to the timezone.")
+	flag.StringVar(&config.Timezone, "timezone", os.Getenv("TZ"), "Timezone of the calendar aware computations, e.g. Europe/Amsterdam.")
+	flag.StringVar(&config.StalenessBasis, "stalenessBasis", os.Getenv("STALENESS_BASIS"), "Timestamp the time of gitlab_merge_request_updated is measured from: updated or created.")
+	flag.StringVar(&config.OpenAgeBuckets, "openAgeBuckets", os.Getenv("OPEN_AGE_BUCKETS"), "Comma separated list of ascending durations used as age buckets for open merge requests.")
+	flag.StringVar(&config.ApprovalSLA, "approvalSLA", os.Getenv("APPROVAL_SLA"), "Duration after which open merge requests with approvals left breach the approval SLA, e.g. 48h.")
+	flag.StringVar(&config.DurationBuckets, "durationBuckets", os.Getenv("DURATION_BUCKETS"), "Comma separated list of ascending buckets in seconds for the merge request duration and lead time histograms.")
+	flag.StringVar(&config.LatencyBuckets, "latencyBuckets", os.Getenv("LATENCY_BUCKETS"), "Comma separated list of ascending buckets in seconds for the API latency histogram.")
+	flag.BoolVar(&config.GroupLabel, "groupLabel", os.Getenv("GROUP_LABEL") == "true", "Add a group label to the project info metric, derived from the namespace of the project.")
+	flag.StringVar(&config.ProjectLabel, "projectLabel", os.Getenv("PROJECT_LABEL"), "Add a short project label to the project and merge request info metrics: name or slug.")
+	flag.StringVar(&config.GroupDepth, "groupDepth", os.Getenv("GROUP_DEPTH"), "Amount of namespace components used for the group label.")
+	flag.BoolVar(&config.LeadTimePerProject, "leadTimePerProject", os.Getenv("LEAD_TIME_PER_PROJECT") == "true", "Partition the merge request lead time histogram by project.")
+	flag.BoolVar(&config.GoMetrics, "goMetrics", os.Getenv("GO_METRICS") != "false", "Expose the Go runtime metrics of the exporter, e.g. goroutines and heap.")
+	flag.BoolVar(&config.ProcessMetrics, "processMetrics", os.Getenv("PROCESS_METRICS") != "false", "Expose the process metrics of the exporter, e.g. CPU and open file descriptors.")
+	flag.BoolVar(&config.SeriesMetrics, "seriesMetrics", os.Getenv("SERIES_METRICS") == "true", "Expose the amount of metrics sent per metric family on every scrape.")
+	flag.BoolVar(&config.CollectCIMinutes, "collectCIMinutes", os.Getenv("COLLECT_CI_MINUTES") == "true", "Collect the CI minutes consumed by the jobs of each project.")
+	flag.BoolVar(&config.CollectPipelines, "collectPipelines", os.Getenv("COLLECT_PIPELINES") == "true", "Collect the status of the latest pipeline on the default branch of each project.")
+	flag.BoolVar(&config.CollectIssues, "collectIssues", os.Getenv("COLLECT_ISSUES") == "true", "Collect the open issues of each project.")
+	flag.BoolVar(&config.CollectForcePushes, "collectForcePushes", os.Getenv("COLLECT_FORCE_PUSHES") == "true", "Check approved open merge requests for force-pushes after the last approval.")
+	flag.BoolVar(&config.CollectChangesRequested, "collectChangesRequested", os.Getenv("COLLECT_CHANGES_REQUESTED") == "true", "Count the reviewers that requested changes on open merge requests.")
+	flag.BoolVar(&config.CollectReviewRounds, "collectReviewRounds", os.Getenv("COLLECT_REVIEW_ROUNDS") == "true", "Estimate the amount of review rounds on open merge requests.")
+	flag.BoolVar(&config.CollectDiscussions, "collectDiscussions", os.Getenv("COLLECT_DISCUSSIONS") == "true", "Count the discussions on open merge requests by whether the author or a reviewer started them.")
+	flag.BoolVar(&config.CollectApprovalRules, "collectApprovalRules", os.Getenv("COLLECT_APPROVAL_RULES") == "true", "Count the approval rules configured on each project.")
+	flag.BoolVar(&config.CollectReopens, "collectReopens", os.Getenv("COLLECT_REOPENS") == "true", "Count the merge requests per project that were reopened within the last 7 days.")
+	flag.BoolVar(&config.CollectStateDurations, "collectStateDurations", os.Getenv("COLLECT_STATE_DURATIONS") == "true", "Collect the average time merged merge requests spent as draft, in review and approved per project.")
+	flag.BoolVar(&config.CollectApprovers, "collectApprovers", os.Getenv("COLLECT_APPROVERS") == "true", "Count the approvals given within the window per approver.")
+	flag.BoolVar(&config.CollectProtectedBranches, "collectProtectedBranches", os.Getenv("COLLECT_PROTECTED_BRANCHES") == "true", "Retrieve the approvals required by the protection of the target branches of open merge requests.")
+	flag.BoolVar(&config.CollectCommits, "collectCommits", os.Getenv("COLLECT_COMMITS") == "true", "Count the commits of the last 7 days on the default branch of each project.")
+	flag.BoolVar(&config.CollectCommitAuthors, "collectCommitAuthors", os.Getenv("COLLECT_COMMIT_AUTHORS") == "true", "Count the distinct commit authors of the default branch per project.")
 }
 
 func main() {
@@ -38,25 +117,96 @@ func main() {
 	log.Info("Starting Gitlab Extra Exporter")
 
 	client := client.New(config)
-	coll := collector.New(client)
-	prometheus.MustRegister(coll)
+	registry := prometheus.NewRegistry()
+	if config.GoMetrics {
+		registry.MustRegister(collectors.NewGoCollector())
+	}
+	if config.ProcessMetrics {
+		registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	}
+
+	coll := collector.New(client, config)
+	registry.MustRegister(coll)
+
+	if config.PushgatewayURL != "" {
+		pusher := push.New(config.PushgatewayURL, config.PushJob).Collector(coll)
+		client.OnScrape(func() {
+			if err := pusher.Push(); err != nil {
+				log.Error("Unable to push metrics to the Pushgateway: ", err)
+			}
+		})
+	}
 
 	log.Info("Start serving metrics")
 
-	http.Handle(config.ListenPath, promhttp.Handler())
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	http.Handle(config.PathPrefix+config.ListenPath, allowMethods(promhttp.InstrumentMetricHandler(
+		registry, promhttp.HandlerFor(registry, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	), http.MethodGet))
+
+	publicLink := ""
+	if config.PublicListenPath != "" {
+		publicRegistry := prometheus.NewRegistry()
+		publicRegistry.MustRegister(collector.NewPublic(client, config))
+
+		http.Handle(config.PathPrefix+config.PublicListenPath, allowMethods(promhttp.InstrumentMetricHandler(
+			publicRegistry, promhttp.HandlerFor(publicRegistry, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+		), http.MethodGet))
+		publicLink = `<p><a href="` + config.PathPrefix + config.PublicListenPath + `">Public metrics</a></p>`
+	}
+
+	http.Handle(config.PathPrefix+"/", allowMethods(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_, err := w.Write([]byte(`<html>
 			<head><title>Gitlab Extra Exporter</title></head>
 			<body>
 			<h1>Gitlab Extra Exporter</h1>
-			<p><a href="` + config.ListenPath + `">Metrics</a></p>
+			<p><a href="` + config.PathPrefix + config.ListenPath + `">Metrics</a></p>
+			` + publicLink + `
 			</body>
 			</html>`))
 		if err != nil {
 			log.Error(err)
 		}
+	}), http.MethodGet))
+
+	server := &http.Server{Addr: ":" + config.ListenAddress}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+
+	drainPeriod, _ := strconv.Atoi(config.DrainPeriod)
+	log.Info("Shutting down, letting active requests finish for at most ", drainPeriod, " seconds")
+
+	client.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(drainPeriod)*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error("Unable to finish active requests: ", err)
+	}
+}
+
+//allowMethods only passes the requests with one of the given methods to the handler, other requests get a 405 with the allowed methods.
+func allowMethods(handler http.Handler, methods ...string) http.Handler {
+	allowed := strings.Join(methods, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, method := range methods {
+			if r.Method == method {
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		w.Header().Set("Allow", allowed)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 	})
-	log.Fatal(http.ListenAndServe(":"+config.ListenAddress, nil))
 }
 
 func parseConfig() error {
@@ -70,23 +220,260 @@ func parseConfig() error {
 			}
 		}
 		if f.Name == "listenAddress" && (f.Value.String() == "" || f.Value.String() == "0") {
-			err = f.Value.Set("8080")
-			if err != nil {
-				log.Error(err)
+			if setErr := f.Value.Set("8080"); setErr != nil {
+				log.Error(setErr)
 			}
 		}
 		if f.Name == "listenPath" && (f.Value.String() == "" || f.Value.String() == "0") {
-			err = f.Value.Set("/metrics")
-			if err != nil {
-				log.Error(err)
+			if setErr := f.Value.Set("/metrics"); setErr != nil {
+				log.Error(setErr)
+			}
+		}
+		if f.Name == "interval" && f.Value.String() == "" {
+			if setErr := f.Value.Set("60"); setErr != nil {
+				log.Error(setErr)
+			}
+		}
+		if f.Name == "pushJob" && f.Value.String() == "" {
+			if setErr := f.Value.Set("gitlab-extra-exporter"); setErr != nil {
+				log.Error(setErr)
+			}
+		}
+		if f.Name == "mrScope" && f.Value.String() == "" {
+			if setErr := f.Value.Set("all"); setErr != nil {
+				log.Error(setErr)
+			}
+		}
+		if f.Name == "businessDays" && f.Value.String() == "" {
+			if setErr := f.Value.Set("Mon,Tue,Wed,Thu,Fri"); setErr != nil {
+				log.Error(setErr)
+			}
+		}
+		if f.Name == "timezone" && f.Value.String() == "" {
+			if setErr := f.Value.Set("UTC"); setErr != nil {
+				log.Error(setErr)
 			}
 		}
-		if f.Name == "interval" && (f.Value.String() == "" || f.Value.String() == "0") {
-			err = f.Value.Set("60")
-			if err != nil {
-				log.Error(err)
+		if f.Name == "stalenessBasis" && f.Value.String() == "" {
+			if setErr := f.Value.Set("updated"); setErr != nil {
+				log.Error(setErr)
+			}
+		}
+		if f.Name == "clientRetries" && f.Value.String() == "" {
+			if setErr := f.Value.Set("3"); setErr != nil {
+				log.Error(setErr)
+			}
+		}
+		if f.Name == "drainPeriod" && f.Value.String() == "" {
+			if setErr := f.Value.Set("10"); setErr != nil {
+				log.Error(setErr)
+			}
+		}
+		if f.Name == "collectTimeout" && f.Value.String() == "" {
+			if setErr := f.Value.Set("10"); setErr != nil {
+				log.Error(setErr)
+			}
+		}
+		if f.Name == "openAgeBuckets" && f.Value.String() == "" {
+			if setErr := f.Value.Set("24h,72h,168h"); setErr != nil {
+				log.Error(setErr)
+			}
+		}
+		if f.Name == "latencyThresholds" && f.Value.String() == "" {
+			if setErr := f.Value.Set("250ms,1s"); setErr != nil {
+				log.Error(setErr)
+			}
+		}
+		if f.Name == "groupDepth" && f.Value.String() == "" {
+			if setErr := f.Value.Set("1"); setErr != nil {
+				log.Error(setErr)
 			}
 		}
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	config.PathPrefix = strings.TrimSuffix(config.PathPrefix, "/")
+	if config.PathPrefix != "" && !strings.HasPrefix(config.PathPrefix, "/") {
+		return fmt.Errorf("pathPrefix must start with a /, got %q", config.PathPrefix)
+	}
+
+	if config.PublicListenPath != "" && (!strings.HasPrefix(config.PublicListenPath, "/") || config.PublicListenPath == config.ListenPath || config.PublicListenPath == "/") {
+		return fmt.Errorf("publicListenPath must start with a / and differ from listenPath and /, got %q", config.PublicListenPath)
+	}
+
+	if _, locationErr := time.LoadLocation(config.Timezone); locationErr != nil {
+		return fmt.Errorf("timezone must be a timezone like Europe/Amsterdam, got %q", config.Timezone)
+	}
+	if config.BusinessTimezone == "" {
+		config.BusinessTimezone = config.Timezone
+	}
+
+	if interval, convErr := strconv.Atoi(config.Interval); convErr != nil || interval < 1 {
+		return fmt.Errorf("interval must be a positive number, got %q", config.Interval)
+	}
+
+	if config.DetailInterval == "" {
+		config.DetailInterval = config.Interval
+	}
+	if interval, convErr := strconv.Atoi(config.DetailInterval); convErr != nil || interval < 1 {
+		return fmt.Errorf("detailInterval must be a positive number, got %q", config.DetailInterval)
+	}
+
+	if config.Retention != "" {
+		if _, durationErr := time.ParseDuration(config.Retention); durationErr != nil {
+			return fmt.Errorf("retention is not a valid duration: %v", durationErr)
+		}
+	}
+
+	if config.MaxDetailFetches != "" {
+		if max, convErr := strconv.Atoi(config.MaxDetailFetches); convErr != nil || max < 0 {
+			return fmt.Errorf("maxDetailFetches must be a non-negative number, got %q", config.MaxDetailFetches)
+		}
+	}
+
+	if config.ChangesRetryDelay != "" {
+		if delay, convErr := strconv.Atoi(config.ChangesRetryDelay); convErr != nil || delay < 0 {
+			return fmt.Errorf("changesRetryDelay must be a non-negative number, got %q", config.ChangesRetryDelay)
+		}
+	}
+
+	if (config.ClientCertFile == "") != (config.ClientKeyFile == "") {
+		return fmt.Errorf("clientCertFile and clientKeyFile must be provided together")
+	}
+
+	if config.ClientCertFile != "" {
+		if _, certErr := tls.LoadX509KeyPair(config.ClientCertFile, config.ClientKeyFile); certErr != nil {
+			return fmt.Errorf("unable to load the client certificate: %v", certErr)
+		}
+	}
+
+	if config.MinProjectActivity != "" {
+		if activity, durationErr := time.ParseDuration(config.MinProjectActivity); durationErr != nil || activity <= 0 {
+			return fmt.Errorf("minProjectActivity must be a positive duration, got %q", config.MinProjectActivity)
+		}
+	}
+
+	if config.RequestDelay != "" {
+		if delay, durationErr := time.ParseDuration(config.RequestDelay); durationErr != nil || delay < 0 {
+			return fmt.Errorf("requestDelay must be a non-negative duration, got %q", config.RequestDelay)
+		}
+	}
+
+	if config.GlobalConcurrency != "" {
+		if concurrency, convErr := strconv.Atoi(config.GlobalConcurrency); convErr != nil || concurrency < 0 {
+			return fmt.Errorf("globalConcurrency must be a non-negative number, got %q", config.GlobalConcurrency)
+		}
+	}
+
+	if config.MRScope != "all" && config.MRScope != "created_by_me" && config.MRScope != "assigned_to_me" {
+		return fmt.Errorf("mrScope must be all, created_by_me or assigned_to_me, got %q", config.MRScope)
+	}
+
+	if config.BusinessHours != "" {
+		if _, calendarErr := internal.ParseBusinessCalendar(config.BusinessHours, config.BusinessDays, config.BusinessTimezone); calendarErr != nil {
+			return fmt.Errorf("business calendar is invalid: %v", calendarErr)
+		}
+	}
+
+	if config.StalenessBasis != "updated" && config.StalenessBasis != "created" {
+		return fmt.Errorf("stalenessBasis must be updated or created, got %q", config.StalenessBasis)
+	}
+
+	if config.WindowBy != "" && config.WindowBy != "updated_at" && config.WindowBy != "created_at" {
+		return fmt.Errorf("windowBy must be updated_at or created_at, got %q", config.WindowBy)
+	}
+
+	if config.MROrderBy != "" && config.MROrderBy != "created_at" && config.MROrderBy != "updated_at" {
+		return fmt.Errorf("mrOrderBy must be created_at or updated_at, got %q", config.MROrderBy)
+	}
+
+	if config.MRSort != "" && config.MRSort != "asc" && config.MRSort != "desc" {
+		return fmt.Errorf("mrSort must be asc or desc, got %q", config.MRSort)
+	}
+
+	if timeout, convErr := strconv.Atoi(config.CollectTimeout); convErr != nil || timeout < 0 {
+		return fmt.Errorf("collectTimeout must be a non-negative number, got %q", config.CollectTimeout)
+	}
+
+	if retries, convErr := strconv.Atoi(config.ClientRetries); convErr != nil || retries < 0 {
+		return fmt.Errorf("clientRetries must be a non-negative number, got %q", config.ClientRetries)
+	}
+
+	if period, convErr := strconv.Atoi(config.DrainPeriod); convErr != nil || period < 0 {
+		return fmt.Errorf("drainPeriod must be a non-negative number, got %q", config.DrainPeriod)
+	}
+
+	if config.MaxSeries != "" {
+		if series, convErr := strconv.Atoi(config.MaxSeries); convErr != nil || series < 0 {
+			return fmt.Errorf("maxSeries must be a non-negative number, got %q", config.MaxSeries)
+		}
+	}
+
+	if config.MaxTitleLength != "" {
+		if length, convErr := strconv.Atoi(config.MaxTitleLength); convErr != nil || length < 0 {
+			return fmt.Errorf("maxTitleLength must be a non-negative number, got %q", config.MaxTitleLength)
+		}
+	}
+
+	for _, pattern := range strings.Split(config.PathFilter, ",") {
+		if _, matchErr := path.Match(strings.TrimSpace(pattern), ""); matchErr != nil {
+			return fmt.Errorf("pathFilter has an invalid pattern %q: %v", pattern, matchErr)
+		}
+	}
+
+	for _, pattern := range strings.Split(config.ExcludeTargetBranches, ",") {
+		if _, matchErr := path.Match(strings.TrimSpace(pattern), ""); matchErr != nil {
+			return fmt.Errorf("excludeTargetBranches has an invalid pattern %q: %v", pattern, matchErr)
+		}
+	}
+
+	if _, regexpErr := regexp.Compile(config.TitleRedactPattern); regexpErr != nil {
+		return fmt.Errorf("titleRedactPattern is invalid: %v", regexpErr)
+	}
+
+	if _, bucketErr := internal.ParseDurations(config.OpenAgeBuckets); bucketErr != nil {
+		return fmt.Errorf("openAgeBuckets is invalid: %v", bucketErr)
+	}
+
+	thresholds, thresholdErr := internal.ParseDurations(config.LatencyThresholds)
+	if thresholdErr != nil {
+		return fmt.Errorf("latencyThresholds is invalid: %v", thresholdErr)
+	}
+	if len(thresholds) != 2 {
+		return fmt.Errorf("latencyThresholds must be two durations, got %q", config.LatencyThresholds)
+	}
+
+	if _, intervalErr := internal.ParseProjectIntervals(config.ProjectIntervals); intervalErr != nil {
+		return fmt.Errorf("projectIntervals is invalid: %v", intervalErr)
+	}
+
+	if config.ApprovalSLA != "" {
+		if sla, durationErr := time.ParseDuration(config.ApprovalSLA); durationErr != nil || sla <= 0 {
+			return fmt.Errorf("approvalSLA must be a positive duration, got %q", config.ApprovalSLA)
+		}
+	}
+
+	if config.DurationBuckets != "" {
+		if _, bucketErr := internal.ParseBuckets(config.DurationBuckets); bucketErr != nil {
+			return fmt.Errorf("durationBuckets is invalid: %v", bucketErr)
+		}
+	}
+
+	if config.LatencyBuckets != "" {
+		if _, bucketErr := internal.ParseBuckets(config.LatencyBuckets); bucketErr != nil {
+			return fmt.Errorf("latencyBuckets is invalid: %v", bucketErr)
+		}
+	}
+
+	if config.ProjectLabel != "" && config.ProjectLabel != "name" && config.ProjectLabel != "slug" {
+		return fmt.Errorf("projectLabel must be name or slug, got %q", config.ProjectLabel)
+	}
+
+	if depth, convErr := strconv.Atoi(config.GroupDepth); convErr != nil || depth < 1 {
+		return fmt.Errorf("groupDepth must be a positive number, got %q", config.GroupDepth)
+	}
+
+	return nil
 }
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// The timezone database is embedded, the alpine image doesn't contain it.
+	_ "time/tzdata"
+)
+
+//BusinessCalendar holds the working hours and working days, in the timezone they apply to.
+type BusinessCalendar struct {
+	location *time.Location
+	days     map[time.Weekday]bool
+	start    time.Duration
+	end      time.Duration
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+//ParseBusinessCalendar parses working hours like 09:00-17:00, a comma separated list of working days like Mon,Tue and a timezone like Europe/Amsterdam.
+func ParseBusinessCalendar(hours string, days string, timezone string) (*BusinessCalendar, error) {
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := strings.SplitN(hours, "-", 2)
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("%q is not of the form 09:00-17:00", hours)
+	}
+
+	start, err := parseClock(bounds[0])
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(bounds[1])
+	if err != nil {
+		return nil, err
+	}
+	if end <= start {
+		return nil, fmt.Errorf("end of the working hours %q isn't after the start", hours)
+	}
+
+	calendar := &BusinessCalendar{location: location, days: map[time.Weekday]bool{}, start: start, end: end}
+
+	for _, day := range strings.Split(days, ",") {
+		weekday, ok := weekdays[strings.ToLower(strings.TrimSpace(day))]
+		if !ok {
+			return nil, fmt.Errorf("%q is not a day like Mon", day)
+		}
+		calendar.days[weekday] = true
+	}
+
+	return calendar, nil
+}
+
+//parseClock parses a time of day like 09:00 into the duration since midnight.
+func parseClock(value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day like 09:00", value)
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+//Duration returns the part of the time between from and to that falls within the working hours on working days.
+func (b *BusinessCalendar) Duration(from time.Time, to time.Time) time.Duration {
+
+	from = from.In(b.location)
+	to = to.In(b.location)
+
+	var total time.Duration
+
+	for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, b.location); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !b.days[day.Weekday()] {
+			continue
+		}
+
+		start := b.at(day, b.start)
+		end := b.at(day, b.end)
+		if from.After(start) {
+			start = from
+		}
+		if to.Before(end) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+
+	return total
+}
+
+//at returns the time of day on the given day, which differs from adding the offset to midnight on days with a daylight saving change.
+func (b *BusinessCalendar) at(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, b.location)
+}
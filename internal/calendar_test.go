@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusinessCalendarDuration(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, amsterdam)
+	}
+
+	tests := []struct {
+		name     string
+		hours    string
+		days     string
+		from     time.Time
+		to       time.Time
+		duration time.Duration
+	}{
+		{
+			name:     "friday to monday",
+			hours:    "09:00-17:00",
+			days:     "Mon,Tue,Wed,Thu,Fri",
+			from:     at(2021, time.October, 15, 15, 0),
+			to:       at(2021, time.October, 18, 11, 0),
+			duration: 4 * time.Hour,
+		},
+		{
+			name:     "start and end outside the working hours",
+			hours:    "09:00-17:00",
+			days:     "Mon,Tue,Wed,Thu,Fri",
+			from:     at(2021, time.October, 12, 7, 0),
+			to:       at(2021, time.October, 13, 20, 0),
+			duration: 16 * time.Hour,
+		},
+		{
+			name:     "within a single day",
+			hours:    "09:00-17:00",
+			days:     "Mon,Tue,Wed,Thu,Fri",
+			from:     at(2021, time.October, 12, 10, 30),
+			to:       at(2021, time.October, 12, 12, 0),
+			duration: 90 * time.Minute,
+		},
+		{
+			name:     "in another timezone than the calendar",
+			hours:    "09:00-17:00",
+			days:     "Mon,Tue,Wed,Thu,Fri",
+			from:     time.Date(2021, time.October, 12, 5, 0, 0, 0, time.UTC),
+			to:       time.Date(2021, time.October, 12, 9, 0, 0, 0, time.UTC),
+			duration: 2 * time.Hour,
+		},
+		{
+			name:     "clocks moving forward",
+			hours:    "01:00-05:00",
+			days:     "Sun",
+			from:     at(2021, time.March, 28, 0, 0),
+			to:       at(2021, time.March, 28, 6, 0),
+			duration: 3 * time.Hour,
+		},
+		{
+			name:     "clocks moving back",
+			hours:    "01:00-05:00",
+			days:     "Sun",
+			from:     at(2021, time.October, 31, 0, 0),
+			to:       at(2021, time.October, 31, 6, 0),
+			duration: 5 * time.Hour,
+		},
+		{
+			name:     "from after to",
+			hours:    "09:00-17:00",
+			days:     "Mon,Tue,Wed,Thu,Fri",
+			from:     at(2021, time.October, 13, 12, 0),
+			to:       at(2021, time.October, 12, 12, 0),
+			duration: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calendar, err := ParseBusinessCalendar(test.hours, test.days, "Europe/Amsterdam")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if duration := calendar.Duration(test.from, test.to); duration != test.duration {
+				t.Errorf("expected %v, got %v", test.duration, duration)
+			}
+		})
+	}
+}
+
+func TestParseBusinessCalendarInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		hours    string
+		days     string
+		timezone string
+	}{
+		{name: "hours without a range", hours: "09:00", days: "Mon", timezone: "UTC"},
+		{name: "hours that aren't a time of day", hours: "9am-5pm", days: "Mon", timezone: "UTC"},
+		{name: "end before the start", hours: "17:00-09:00", days: "Mon", timezone: "UTC"},
+		{name: "end equal to the start", hours: "09:00-09:00", days: "Mon", timezone: "UTC"},
+		{name: "unknown day", hours: "09:00-17:00", days: "Mon,Funday", timezone: "UTC"},
+		{name: "empty days", hours: "09:00-17:00", days: "", timezone: "UTC"},
+		{name: "unknown timezone", hours: "09:00-17:00", days: "Mon", timezone: "Europe/Atlantis"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ParseBusinessCalendar(test.hours, test.days, test.timezone); err == nil {
+				t.Errorf("expected an error for hours %q, days %q and timezone %q", test.hours, test.days, test.timezone)
+			}
+		})
+	}
+}
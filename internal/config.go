@@ -4,7 +4,96 @@ package internal
 type Config struct {
 	ListenAddress string
 	ListenPath    string
-	GitlabURI     string
-	GitlabAPIKey  string
-	Interval      string
+	PathPrefix    string
+
+	PublicListenPath string
+
+	PushgatewayURL string
+	PushJob        string
+
+	GitlabURI      string
+	GitlabAPIKey   string
+	Interval       string
+	DetailInterval string
+
+	ClientCertFile string
+	ClientKeyFile  string
+	SudoUser       string
+	ClientRetries  string
+
+	CollectTimeout string
+	MaxSeries      string
+	DrainPeriod    string
+	Retention      string
+
+	MaxDetailFetches  string
+	ChangesRetryDelay string
+	GlobalConcurrency string
+	RequestDelay      string
+	Milestone         string
+	TargetBranch      string
+	MRScope           string
+	WindowBy          string
+	MROrderBy         string
+	MRSort            string
+
+	TrackedLabels string
+
+	PinnedProjects     string
+	MinProjectActivity string
+	Membership         bool
+	ProjectIntervals   string
+	IncludeForks       bool
+	OnlyUnapproved     bool
+
+	ChangeExtensions string
+
+	ExcludeTargetBranches string
+	PathFilter            string
+
+	TitleRedactPattern string
+	MaxTitleLength     string
+	DropTitleLabel     bool
+
+	DropInternalIDLabel bool
+
+	OpenAgeBuckets string
+	ApprovalSLA    string
+	StalenessBasis string
+
+	Timezone string
+
+	BusinessHours    string
+	BusinessDays     string
+	BusinessTimezone string
+
+	LatencyThresholds string
+	DurationBuckets   string
+	LatencyBuckets    string
+
+	ProjectLabel string
+	GroupLabel   bool
+	GroupDepth   string
+
+	LeadTimePerProject bool
+
+	GoMetrics      bool
+	ProcessMetrics bool
+	SeriesMetrics  bool
+
+	CollectCommits          bool
+	CollectCommitAuthors    bool
+	CollectCIMinutes        bool
+	CollectPipelines        bool
+	CollectIssues           bool
+	CollectForcePushes      bool
+	CollectChangesRequested bool
+	CollectReviewRounds     bool
+	CollectDiscussions      bool
+	CollectApprovers        bool
+	CollectStateDurations   bool
+
+	CollectProtectedBranches bool
+	CollectReopens           bool
+	CollectApprovalRules     bool
 }
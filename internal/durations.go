@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ParseDurations parses a comma separated list of positive durations in ascending order.
+func ParseDurations(value string) ([]time.Duration, error) {
+	var result []time.Duration
+
+	for _, part := range strings.Split(value, ",") {
+		duration, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("duration %v is not positive", duration)
+		}
+		if len(result) > 0 && duration <= result[len(result)-1] {
+			return nil, fmt.Errorf("duration %v is not in ascending order", duration)
+		}
+		result = append(result, duration)
+	}
+
+	return result, nil
+}
+
+//ParseBuckets parses a comma separated list of positive histogram buckets in seconds in ascending order.
+func ParseBuckets(value string) ([]float64, error) {
+	var result []float64
+
+	for _, part := range strings.Split(value, ",") {
+		bucket, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		if bucket <= 0 {
+			return nil, fmt.Errorf("bucket %v is not positive", bucket)
+		}
+		if len(result) > 0 && bucket <= result[len(result)-1] {
+			return nil, fmt.Errorf("bucket %v is not in ascending order", bucket)
+		}
+		result = append(result, bucket)
+	}
+
+	return result, nil
+}
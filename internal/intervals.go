@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ParseProjectIntervals parses a comma separated list of project paths with their interval in seconds, e.g. group/project=15.
+func ParseProjectIntervals(value string) (map[string]time.Duration, error) {
+	result := map[string]time.Duration{}
+
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		pair := strings.SplitN(part, "=", 2)
+		if len(pair) != 2 || strings.TrimSpace(pair[0]) == "" {
+			return nil, fmt.Errorf("%q is not of the form path=seconds", part)
+		}
+
+		seconds, err := strconv.Atoi(strings.TrimSpace(pair[1]))
+		if err != nil || seconds < 1 {
+			return nil, fmt.Errorf("interval of %s must be a positive number of seconds, got %q", strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1]))
+		}
+
+		result[strings.TrimSpace(pair[0])] = time.Duration(seconds) * time.Second
+	}
+
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//ApprovalRuleCountStats is the struct for the amount of approval rules configured on a project.
+type ApprovalRuleCountStats struct {
+	ProjectID string
+	Rules     int
+}
+
+//getApprovalRuleCounts counts the project level approval rules of the projects.
+//Projects of which the approval rules aren't available, e.g. on Gitlab CE, are left out.
+func getApprovalRuleCounts(ctx context.Context, c *gitlab.Client, projects []ProjectStats) (*[]ApprovalRuleCountStats, error) {
+
+	results := make([]*ApprovalRuleCountStats, len(projects))
+
+	err := forEach(len(projects), func(i int) error {
+		project := projects[i]
+
+		rules, resp, err := c.Projects.GetProjectApprovalRules(project.ID, gitlab.WithContext(ctx))
+		if err != nil {
+			if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		results[i] = &ApprovalRuleCountStats{ProjectID: project.ID, Rules: len(rules)}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ApprovalRuleCountStats
+	for _, counts := range results {
+		if counts != nil {
+			result = append(result, *counts)
+		}
+	}
+
+	return &result, nil
+}
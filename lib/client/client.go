@@ -2,8 +2,14 @@
 package client
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -19,7 +25,32 @@ type Stats struct {
 	MergeRequestsClosed *[]MergeClosedStats
 	MergeRequestsMerged *[]MergeMergedStats
 	Approvals           *[]ApprovalStats
+	MergedApprovals     *[]ApprovalStats
 	Changes             *[]ChangeStats
+	Updates             *[]UpdateStats
+	CommitAuthors       *[]CommitAuthorStats
+	CommitCounts        *[]CommitCountStats
+	Pickups             *[]PickupStats
+	LabelEvents         *[]LabelEventStats
+	CIMinutes           *[]CIMinutesStats
+	PipelineStatuses    *[]PipelineStatusStats
+	Issues              *[]IssueStats
+	PipelineRetries     *[]PipelineRetryStats
+	ForcePushes         *[]ForcePushStats
+	ChangesRequested    *[]ChangesRequestedStats
+	ReviewRounds        *[]ReviewRoundStats
+	Discussions         *[]DiscussionStats
+	StateDurations      *[]StateDurationStats
+	ApproverApprovals   *[]ApproverApprovalStats
+	ProtectedBranches   *[]ProtectedBranchStats
+	Reopens             *[]ReopenStats
+	ApprovalRuleCounts  *[]ApprovalRuleCountStats
+	Filtered            *[]FilteredStats
+
+	DetailFetchTruncated bool
+
+	//TokenExpiresAt is nil when the API key doesn't expire or Gitlab doesn't expose its expiry.
+	TokenExpiresAt *time.Time
 }
 
 //ExporterClient contains Gitlab information for connecting
@@ -27,112 +58,865 @@ type ExporterClient struct {
 	gitlabURI    string
 	gitlabAPIKey string
 	httpClient   *http.Client
-	interval     time.Duration
+	transport    *transport
+
+	//gitlab is reused across scrapes, it is nil when constructing it failed.
+	gitlab   *gitlab.Client
+	interval time.Duration
+
+	detailInterval time.Duration
+
+	maxDetailFetches        int
+	changesRetryDelay       time.Duration
+	milestone               string
+	targetBranch            string
+	mrScope                 string
+	windowBy                string
+	mrOrderBy               string
+	mrSort                  string
+	collectCommitAuthors    bool
+	collectCommits          bool
+	collectCIMinutes        bool
+	collectPipelines        bool
+	collectIssues           bool
+	collectForcePushes      bool
+	collectChangesRequested bool
+	collectReviewRounds     bool
+	collectDiscussions      bool
+	collectStateDurations   bool
+	collectApprovers        bool
+	collectProtected        bool
+	collectReopens          bool
+	collectApprovalRules    bool
+	includeForks            bool
+	onlyUnapproved          bool
+	trackedLabels           []string
+	changeExtensions        []string
+	excludeTargetBranches   []string
+	pathFilter              []string
+	pinnedProjects          []string
+	minProjectActivity      time.Duration
+	membership              bool
+	projectIntervals        map[string]time.Duration
+
+	//State kept across scrapes to detect updates on merge requests.
+	mutex        sync.Mutex
+	lastUpdated  map[string]time.Time
+	updateCounts map[string]int
+
+	scrapeFailures int
+	lastScrapeErr  *ScrapeError
+	//lastScrapeErrBy is the scrape that failed, only that scrape clears the error again when it succeeds.
+	lastScrapeErrBy string
+	compareSkips    int
+	detailFetches   map[string]int
+
+	mergeErrorSkips map[string]int
+
+	//projectsLastSeen is kept for projects that aren't listed anymore, so they can be detected as stale.
+	projectsLastSeen map[string]time.Time
+
+	//approvalsAvailable is set once an approvals request succeeded, after which a 403 or 404 only concerns a single MR.
+	approvalsAvailable   bool
+	approvalsUnavailable bool
+	currentUserID        int
+
+	statisticsMissingLogged bool
+
+	//The API latency quantiles of the requests of the most recent background scrape.
+	latencySeen bool
+	latencyP50  time.Duration
+	latencyP95  time.Duration
+
+	//The API latency histogram of all requests, with the upper bounds of the buckets in seconds.
+	latencyBuckets []float64
+	latencyCounts  []uint64
+	latencyCount   uint64
+	latencySum     float64
+
+	store *mergeRequestStore
+
+	//The most recent results of the listing and of the details, composed into CachedStats.
+	listedStats *Stats
+	detailStats *Stats
+
+	onScrape func()
+
+	//ctx is done once the client is stopped, which ends the background scrapes.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 //New returns a new Client connection to Gitlab.
 func New(c internal.Config) *ExporterClient {
 
 	convertedTime, _ := strconv.ParseInt(c.Interval, 10, 64)
+	detailInterval, _ := strconv.ParseInt(c.DetailInterval, 10, 64)
+	maxDetailFetches, _ := strconv.Atoi(c.MaxDetailFetches)
+	changesRetryDelay, _ := strconv.Atoi(c.ChangesRetryDelay)
+	retention, _ := time.ParseDuration(c.Retention)
+	minProjectActivity, _ := time.ParseDuration(c.MinProjectActivity)
+	projectIntervals, _ := internal.ParseProjectIntervals(c.ProjectIntervals)
+
+	latencyBuckets := defaultLatencyBuckets
+	if c.LatencyBuckets != "" {
+		latencyBuckets, _ = internal.ParseBuckets(c.LatencyBuckets)
+	}
+
+	var trackedLabels []string
+	for _, label := range strings.Split(c.TrackedLabels, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			trackedLabels = append(trackedLabels, label)
+		}
+	}
+
+	var pinnedProjects []string
+	for _, project := range strings.Split(c.PinnedProjects, ",") {
+		if project = strings.TrimSpace(project); project != "" {
+			pinnedProjects = append(pinnedProjects, project)
+		}
+	}
+
+	var excludeTargetBranches []string
+	for _, pattern := range strings.Split(c.ExcludeTargetBranches, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			excludeTargetBranches = append(excludeTargetBranches, pattern)
+		}
+	}
+
+	var pathFilter []string
+	for _, pattern := range strings.Split(c.PathFilter, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			pathFilter = append(pathFilter, pattern)
+		}
+	}
+
+	var changeExtensions []string
+	for _, extension := range strings.Split(c.ChangeExtensions, ",") {
+		if extension = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(extension), ".")); extension != "" {
+			changeExtensions = append(changeExtensions, extension)
+		}
+	}
+
+	transport := &transport{next: newBaseTransport(c.ClientCertFile, c.ClientKeyFile), sudo: c.SudoUser}
+	transport.delay, _ = time.ParseDuration(c.RequestDelay)
+	if globalConcurrency, _ := strconv.Atoi(c.GlobalConcurrency); globalConcurrency > 0 {
+		transport.slots = make(chan struct{}, globalConcurrency)
+	}
 
 	exporter := &ExporterClient{
 		gitlabAPIKey: c.GitlabAPIKey,
 		gitlabURI:    c.GitlabURI,
-		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		httpClient:   &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		transport:    transport,
 		interval:     time.Duration(convertedTime),
+		listedStats:  emptyStats(),
+		detailStats:  emptyStats(),
+
+		detailInterval: time.Duration(detailInterval),
+		lastUpdated:    map[string]time.Time{},
+		updateCounts:   map[string]int{},
+		detailFetches:  map[string]int{"opened": 0, "merged": 0, "closed": 0},
+
+		mergeErrorSkips: map[string]int{"merged": 0, "closed": 0},
+
+		projectsLastSeen: map[string]time.Time{},
+
+		maxDetailFetches:        maxDetailFetches,
+		changesRetryDelay:       time.Duration(changesRetryDelay) * time.Second,
+		milestone:               c.Milestone,
+		targetBranch:            c.TargetBranch,
+		mrScope:                 c.MRScope,
+		windowBy:                c.WindowBy,
+		mrOrderBy:               c.MROrderBy,
+		mrSort:                  c.MRSort,
+		collectCommitAuthors:    c.CollectCommitAuthors,
+		collectCommits:          c.CollectCommits,
+		collectCIMinutes:        c.CollectCIMinutes,
+		collectPipelines:        c.CollectPipelines,
+		collectIssues:           c.CollectIssues,
+		collectForcePushes:      c.CollectForcePushes,
+		collectChangesRequested: c.CollectChangesRequested,
+		collectReviewRounds:     c.CollectReviewRounds,
+		collectDiscussions:      c.CollectDiscussions,
+		collectStateDurations:   c.CollectStateDurations,
+		collectApprovers:        c.CollectApprovers,
+		collectProtected:        c.CollectProtectedBranches,
+		collectReopens:          c.CollectReopens,
+		collectApprovalRules:    c.CollectApprovalRules,
+		includeForks:            c.IncludeForks,
+		onlyUnapproved:          c.OnlyUnapproved,
+		trackedLabels:           trackedLabels,
+		changeExtensions:        changeExtensions,
+		excludeTargetBranches:   excludeTargetBranches,
+		pinnedProjects:          pinnedProjects,
+		minProjectActivity:      minProjectActivity,
+		membership:              c.Membership,
+		projectIntervals:        projectIntervals,
+		latencyBuckets:          latencyBuckets,
+		latencyCounts:           make([]uint64, len(latencyBuckets)),
+	}
+
+	exporter.ctx, exporter.cancel = context.WithCancel(context.Background())
+
+	if retention > Window {
+		exporter.store = newMergeRequestStore(retention)
 	}
 
+	clientRetries, _ := strconv.Atoi(c.ClientRetries)
+	glc, err := exporter.connect(clientRetries)
+	if err != nil {
+		log.Error("Unable to construct the Gitlab client, trying again on the next scrape: ", err)
+	}
+	exporter.gitlab = glc
+
 	exporter.startFetchData()
 
 	return exporter
 }
 
 // CachedStats is to store scraped data for caching purposes.
-var CachedStats *Stats = &Stats{
-	Projects:            &[]ProjectStats{},
-	MergeRequests:       &[]MergeRequestStats{},
-	MergeRequestsOpen:   &[]MergeRequestStats{},
-	MergeRequestsClosed: &[]MergeClosedStats{},
-	MergeRequestsMerged: &[]MergeMergedStats{},
-	Approvals:           &[]ApprovalStats{},
-	Changes:             &[]ChangeStats{},
+var CachedStats *Stats = emptyStats()
+
+//emptyStats returns stats without any results.
+func emptyStats() *Stats {
+	return &Stats{
+		Projects:            &[]ProjectStats{},
+		MergeRequests:       &[]MergeRequestStats{},
+		MergeRequestsOpen:   &[]MergeRequestStats{},
+		MergeRequestsClosed: &[]MergeClosedStats{},
+		MergeRequestsMerged: &[]MergeMergedStats{},
+		Approvals:           &[]ApprovalStats{},
+		MergedApprovals:     &[]ApprovalStats{},
+		Changes:             &[]ChangeStats{},
+		Updates:             &[]UpdateStats{},
+		CommitAuthors:       &[]CommitAuthorStats{},
+		CommitCounts:        &[]CommitCountStats{},
+		Pickups:             &[]PickupStats{},
+		LabelEvents:         &[]LabelEventStats{},
+		CIMinutes:           &[]CIMinutesStats{},
+		PipelineStatuses:    &[]PipelineStatusStats{},
+		Issues:              &[]IssueStats{},
+		PipelineRetries:     &[]PipelineRetryStats{},
+		ForcePushes:         &[]ForcePushStats{},
+		ChangesRequested:    &[]ChangesRequestedStats{},
+		ReviewRounds:        &[]ReviewRoundStats{},
+		Discussions:         &[]DiscussionStats{},
+		StateDurations:      &[]StateDurationStats{},
+		ApproverApprovals:   &[]ApproverApprovalStats{},
+		ProtectedBranches:   &[]ProtectedBranchStats{},
+		Reopens:             &[]ReopenStats{},
+		ApprovalRuleCounts:  &[]ApprovalRuleCountStats{},
+		Filtered:            &[]FilteredStats{},
+	}
 }
 
 //GetStats retrieves data from API to create metrics from.
 func (c *ExporterClient) GetStats() (*Stats, error) {
 
-	return CachedStats, nil
+	return c.cachedStats(), nil
+}
+
+//cachedStats returns the cached stats, which are composed again after the listing, the details and the project refreshes.
+func (c *ExporterClient) cachedStats() *Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return CachedStats
 }
 
 func (c *ExporterClient) getData() error {
 
-	glc, err := gitlab.NewClient(c.gitlabAPIKey, gitlab.WithBaseURL(c.gitlabURI), gitlab.WithHTTPClient(c.httpClient))
+	start := time.Now()
+
+	glc, err := c.gitlabClient()
 	if err != nil {
-		return err
+		return withStage("client", err)
 	}
 
-	projects, err := getProjects(glc)
+	projects, resp, err := getProjects(c.ctx, glc, c.minProjectActivity, c.membership)
 	if err != nil {
-		return err
+		if c.transport.sudo != "" && resp != nil && resp.StatusCode == http.StatusForbidden {
+			return withStage("projects", fmt.Errorf("doing requests as %s requires an admin token with the sudo scope: %w", c.transport.sudo, err))
+		}
+		return withStage("projects", err)
 	}
 
-	mrs, err := getMergeRequest(glc)
+	pinnedProjects := addPinnedProjects(c.ctx, glc, *projects, c.pinnedProjects)
+	projects = &pinnedProjects
+
+	c.logMissingStatistics(*projects)
+
+	// The expiry is only needed for a single metric, so it is left out instead of failing the scrape.
+	tokenExpiresAt, err := getTokenExpiry(c.ctx, glc)
 	if err != nil {
-		return err
+		log.Warn("Unable to retrieve the expiry of the API key, omitting it for this scrape: ", err)
 	}
 
-	mrOpen, mrMerged, mrClosed, err := getMergeRequestsDetails(glc, *mrs)
+	var mrs *[]MergeRequestStats
+	filtered := &[]FilteredStats{}
+
+	// The totals of the filters are counted over all MRs of the instance, which doesn't match the listing of the member or active projects.
+	if c.membership || c.minProjectActivity > 0 {
+		mrs, err = getMemberMergeRequests(c.ctx, glc, *projects, c.listMergeRequestsOptions())
+		if err != nil {
+			return withStage("merge_requests", err)
+		}
+	} else {
+		mrs, err = getMergeRequest(c.ctx, glc, c.listMergeRequestsOptions())
+		if err != nil {
+			return withStage("merge_requests", err)
+		}
+
+		filtered, err = c.getFilteredCounts(glc)
+		if err != nil {
+			return withStage("merge_requests", err)
+		}
+	}
+
+	if len(c.excludeTargetBranches) > 0 {
+		included, excluded := withoutTargetBranches(*mrs, c.excludeTargetBranches)
+		mrs = &included
+		*filtered = append(*filtered, FilteredStats{Reason: "excluded_branch", Count: excluded})
+	}
+
+	if len(c.pathFilter) > 0 {
+		included, excluded, err := withPaths(c.ctx, glc, *mrs, c.pathFilter)
+		if err != nil {
+			return withStage("changes", err)
+		}
+		mrs = &included
+		*filtered = append(*filtered, FilteredStats{Reason: "path", Count: excluded})
+	}
+
+	detailMRs, truncated := limitMergeRequests(*mrs, c.maxDetailFetches)
+	if truncated {
+		log.Warn("Found ", len(*mrs), " MRs, only retrieving the details of the ", c.maxDetailFetches, " most recently updated")
+	}
+
+	mrOpen, mrMerged, mrClosed, mergeErrors, err := getMergeRequestsDetails(c.ctx, glc, detailMRs, c.changesRetryDelay)
 	if err != nil {
-		return err
+		return withStage("merge_requests", err)
+	}
+
+	c.mutex.Lock()
+	for _, mr := range detailMRs {
+		c.detailFetches[mr.State]++
+	}
+	for state, count := range mergeErrors {
+		c.mergeErrorSkips[state] += count
 	}
+	c.mutex.Unlock()
 
-	approvals, err := getApprovals(glc, *mrOpen)
+	updates := c.trackMergeRequestUpdates(*mrOpen, *mrMerged, *mrClosed)
+
+	c.mutex.Lock()
+	if c.store != nil {
+		mrMerged, mrClosed = c.store.update(*mrMerged, *mrClosed)
+	}
+
+	listed := emptyStats()
+	listed.Projects = projects
+	listed.MergeRequests = mrs
+	listed.MergeRequestsOpen = mrOpen
+	listed.MergeRequestsClosed = mrClosed
+	listed.MergeRequestsMerged = mrMerged
+	listed.Updates = updates
+	listed.Filtered = filtered
+	listed.DetailFetchTruncated = truncated
+	listed.TokenExpiresAt = tokenExpiresAt
+
+	c.listedStats = listed
+	c.composeStats()
+
+	for _, project := range *projects {
+		c.projectsLastSeen[project.ID] = start
+	}
+
+	failures := c.scrapeFailures
+	c.mutex.Unlock()
+
+	//errors is the amount of failed scrapes since the exporter started.
+	log.WithFields(log.Fields{
+		"projects": len(*projects),
+		"mrs":      len(*mrs),
+		"open":     len(*mrOpen),
+		"merged":   len(*mrMerged),
+		"closed":   len(*mrClosed),
+		"duration": time.Since(start).Round(time.Millisecond).String(),
+		"errors":   failures,
+	}).Info("New data retrieved")
+
+	return nil
+}
+
+//getDetailData retrieves the expensive details of the projects and MRs found by the most recent listing.
+func (c *ExporterClient) getDetailData() error {
+
+	start := time.Now()
+
+	glc, err := c.gitlabClient()
 	if err != nil {
-		return err
+		return withStage("client", err)
+	}
+
+	c.mutex.Lock()
+	listed := c.listedStats
+	c.mutex.Unlock()
+
+	// Merged and closed MRs retained after the window aren't listed anymore and don't need their details again.
+	inWindow := map[string]bool{}
+	for _, mr := range *listed.MergeRequests {
+		inWindow[mr.ID] = true
 	}
 
-	changes, err := getChanges(glc, *mrOpen)
+	mrOpen := *listed.MergeRequestsOpen
+
+	var merged, closed []MergeRequestStats
+	var mrMerged []MergeMergedStats
+	for _, mr := range *listed.MergeRequestsMerged {
+		if inWindow[mr.MergeRequest.ID] {
+			merged = append(merged, mr.MergeRequest)
+			mrMerged = append(mrMerged, mr)
+		}
+	}
+	for _, mr := range *listed.MergeRequestsClosed {
+		if inWindow[mr.MergeRequest.ID] {
+			closed = append(closed, mr.MergeRequest)
+		}
+	}
+
+	approvals, err := c.getAvailableApprovals(glc, mrOpen, true)
+	if err != nil {
+		return withStage("approvals", err)
+	}
+
+	mergedApprovals, err := c.getAvailableApprovals(glc, merged, false)
+	if err != nil {
+		return withStage("approvals", err)
+	}
+
+	changes, skipped, err := getChanges(c.ctx, glc, mrOpen, c.changeExtensions, c.includeForks)
+	if err != nil {
+		return withStage("changes", err)
+	}
+
+	c.mutex.Lock()
+	c.compareSkips += skipped
+	c.mutex.Unlock()
+
+	pickups, err := getPickupTimes(c.ctx, glc, append(append([]MergeRequestStats{}, mrOpen...), merged...))
 	if err != nil {
-		return err
+		return withStage("pickups", err)
+	}
+
+	ciMinutes := &[]CIMinutesStats{}
+	if c.collectCIMinutes {
+		ciMinutes, err = getCIMinutes(c.ctx, glc, *listed.Projects)
+		if err != nil {
+			return withStage("ci_minutes", err)
+		}
+	}
+
+	pipelineStatuses := &[]PipelineStatusStats{}
+	pipelineRetries := &[]PipelineRetryStats{}
+	if c.collectPipelines {
+		pipelineStatuses, err = getPipelineStatuses(c.ctx, glc, *listed.Projects)
+		if err != nil {
+			return withStage("pipelines", err)
+		}
+
+		pipelineRetries, err = getPipelineRetries(c.ctx, glc, mrOpen)
+		if err != nil {
+			return withStage("pipelines", err)
+		}
+	}
+
+	issues := &[]IssueStats{}
+	if c.collectIssues {
+		issues, err = getIssues(c.ctx, glc, *listed.Projects)
+		if err != nil {
+			return withStage("issues", err)
+		}
+	}
+
+	labelEvents := &[]LabelEventStats{}
+	if len(c.trackedLabels) > 0 {
+		labelEvents, err = getLabelEvents(c.ctx, glc, mrOpen, c.trackedLabels)
+		if err != nil {
+			return withStage("labels", err)
+		}
+	}
+
+	changesRequested := &[]ChangesRequestedStats{}
+	if c.collectChangesRequested {
+		changesRequested, err = getChangesRequested(c.ctx, glc, mrOpen)
+		if err != nil {
+			return withStage("reviews", err)
+		}
+	}
+
+	reviewRounds := &[]ReviewRoundStats{}
+	if c.collectReviewRounds {
+		reviewRounds, err = getReviewRounds(c.ctx, glc, mrOpen)
+		if err != nil {
+			return withStage("reviews", err)
+		}
+	}
+
+	discussions := &[]DiscussionStats{}
+	if c.collectDiscussions {
+		discussions, err = getDiscussions(c.ctx, glc, mrOpen)
+		if err != nil {
+			return withStage("reviews", err)
+		}
+	}
+
+	stateDurations := &[]StateDurationStats{}
+	if c.collectStateDurations {
+		stateDurations, err = getStateDurations(c.ctx, glc, mrMerged)
+		if err != nil {
+			return withStage("state_durations", err)
+		}
+	}
+
+	approverApprovals := &[]ApproverApprovalStats{}
+	if c.collectApprovers {
+		approverApprovals, err = getApproverApprovals(c.ctx, glc, append(append(append([]MergeRequestStats{}, mrOpen...), merged...), closed...))
+		if err != nil {
+			return withStage("approvals", err)
+		}
+	}
+
+	forcePushes := &[]ForcePushStats{}
+	if c.collectForcePushes {
+		forcePushes, err = getForcePushes(c.ctx, glc, mrOpen)
+		if err != nil {
+			return withStage("force_pushes", err)
+		}
+	}
+
+	commitAuthors := &[]CommitAuthorStats{}
+	if c.collectCommitAuthors {
+		commitAuthors, err = getCommitAuthors(c.ctx, glc, *listed.Projects)
+		if err != nil {
+			return withStage("commits", err)
+		}
+	}
+
+	commitCounts := &[]CommitCountStats{}
+	if c.collectCommits {
+		commitCounts, err = getCommitCounts(c.ctx, glc, *listed.Projects)
+		if err != nil {
+			return withStage("commits", err)
+		}
+	}
+
+	protectedBranches := &[]ProtectedBranchStats{}
+	if c.collectProtected {
+		protectedBranches = getProtectedBranchApprovals(c.ctx, glc, mrOpen)
 	}
 
-	CachedStats = &Stats{
-		Projects:            projects,
-		MergeRequests:       mrs,
-		MergeRequestsOpen:   mrOpen,
-		MergeRequestsClosed: mrClosed,
-		MergeRequestsMerged: mrMerged,
-		Approvals:           approvals,
-		Changes:             changes,
+	reopens := &[]ReopenStats{}
+	if c.collectReopens {
+		reopens, err = getReopens(c.ctx, glc, append(append(append([]MergeRequestStats{}, mrOpen...), merged...), closed...))
+		if err != nil {
+			return withStage("reopens", err)
+		}
+	}
+
+	approvalRuleCounts := &[]ApprovalRuleCountStats{}
+	if c.collectApprovalRules {
+		approvalRuleCounts, err = getApprovalRuleCounts(c.ctx, glc, *listed.Projects)
+		if err != nil {
+			return withStage("approval_rules", err)
+		}
 	}
 
-	log.Info("New data retrieved.")
+	details := emptyStats()
+	details.Approvals = approvals
+	details.MergedApprovals = mergedApprovals
+	details.Changes = changes
+	details.CommitAuthors = commitAuthors
+	details.CommitCounts = commitCounts
+	details.Pickups = pickups
+	details.LabelEvents = labelEvents
+	details.CIMinutes = ciMinutes
+	details.PipelineStatuses = pipelineStatuses
+	details.Issues = issues
+	details.PipelineRetries = pipelineRetries
+	details.ForcePushes = forcePushes
+	details.ChangesRequested = changesRequested
+	details.ReviewRounds = reviewRounds
+	details.Discussions = discussions
+	details.StateDurations = stateDurations
+	details.ApproverApprovals = approverApprovals
+	details.ProtectedBranches = protectedBranches
+	details.Reopens = reopens
+	details.ApprovalRuleCounts = approvalRuleCounts
+
+	c.mutex.Lock()
+	c.detailStats = details
+	c.composeStats()
+	c.mutex.Unlock()
+
+	log.WithFields(log.Fields{
+		"open":     len(mrOpen),
+		"merged":   len(merged),
+		"duration": time.Since(start).Round(time.Millisecond).String(),
+	}).Info("New details retrieved")
 
 	return nil
 }
 
+//composeStats combines the most recent listing and details into the cached stats, the caller holds the mutex.
+func (c *ExporterClient) composeStats() {
+
+	stats := *c.listedStats
+	stats.Approvals = c.detailStats.Approvals
+	stats.MergedApprovals = c.detailStats.MergedApprovals
+	stats.Changes = c.detailStats.Changes
+	stats.CommitAuthors = c.detailStats.CommitAuthors
+	stats.CommitCounts = c.detailStats.CommitCounts
+	stats.Pickups = c.detailStats.Pickups
+	stats.LabelEvents = c.detailStats.LabelEvents
+	stats.CIMinutes = c.detailStats.CIMinutes
+	stats.PipelineStatuses = c.detailStats.PipelineStatuses
+	stats.Issues = c.detailStats.Issues
+	stats.PipelineRetries = c.detailStats.PipelineRetries
+	stats.ForcePushes = c.detailStats.ForcePushes
+	stats.ChangesRequested = c.detailStats.ChangesRequested
+	stats.ReviewRounds = c.detailStats.ReviewRounds
+	stats.Discussions = c.detailStats.Discussions
+	stats.StateDurations = c.detailStats.StateDurations
+	stats.ApproverApprovals = c.detailStats.ApproverApprovals
+	stats.ProtectedBranches = c.detailStats.ProtectedBranches
+	stats.Reopens = c.detailStats.Reopens
+	stats.ApprovalRuleCounts = c.detailStats.ApprovalRuleCounts
+
+	if c.onlyUnapproved {
+		var approved int
+		stats, approved = withoutApproved(stats)
+
+		filtered := append(append([]FilteredStats{}, *stats.Filtered...), FilteredStats{Reason: "approved", Count: approved})
+		stats.Filtered = &filtered
+	}
+
+	CachedStats = &stats
+}
+
+//ScrapeFailures returns the amount of background scrapes that failed because of an error.
+func (c *ExporterClient) ScrapeFailures() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.scrapeFailures
+}
+
+//CompareSkips returns the amount of merge requests of which the changes were skipped because a compared branch didn't exist.
+func (c *ExporterClient) CompareSkips() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.compareSkips
+}
+
+//DetailFetches returns the amount of merge requests of which the details were retrieved per state.
+func (c *ExporterClient) DetailFetches() map[string]int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result := map[string]int{}
+	for state, count := range c.detailFetches {
+		result[state] = count
+	}
+	return result
+}
+
+//MergeErrorSkips returns the amount of merged and closed merge requests that were left out because of a merge error, per state.
+func (c *ExporterClient) MergeErrorSkips() map[string]int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result := map[string]int{}
+	for state, count := range c.mergeErrorSkips {
+		result[state] = count
+	}
+	return result
+}
+
+//LastScrapeError returns the stage and reason of the most recent failed background scrape, or nil when the most recent scrape succeeded.
+func (c *ExporterClient) LastScrapeError() *ScrapeError {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.lastScrapeErr == nil {
+		return nil
+	}
+	result := *c.lastScrapeErr
+	return &result
+}
+
+//ProjectsLastSeen returns the start of the most recent background scrape that listed the project, per project ID.
+func (c *ExporterClient) ProjectsLastSeen() map[string]time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result := map[string]time.Time{}
+	for project, seen := range c.projectsLastSeen {
+		result[project] = seen
+	}
+	return result
+}
+
+//APIResponses returns the amount of responses of the Gitlab API per status code.
+func (c *ExporterClient) APIResponses() map[int]int {
+	if c.transport == nil {
+		return map[int]int{}
+	}
+	return c.transport.responseCounts()
+}
+
+//RateLimit returns the rate limit reported by Gitlab on the most recent response, ok is false when Gitlab didn't report one.
+func (c *ExporterClient) RateLimit() (remaining float64, limit float64, ok bool) {
+	if c.transport == nil {
+		return 0, 0, false
+	}
+	return c.transport.rateLimit()
+}
+
+//APILatency returns the median and 95th percentile latency of the Gitlab API during the most recent background scrape, ok is false before the first one.
+func (c *ExporterClient) APILatency() (p50 time.Duration, p95 time.Duration, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.latencyP50, c.latencyP95, c.latencySeen
+}
+
+//APILatencyHistogram returns the amount and the sum in seconds of all Gitlab API requests, with the cumulative amount per upper bound of the buckets.
+func (c *ExporterClient) APILatencyHistogram() (count uint64, sum float64, buckets map[float64]uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	buckets = map[float64]uint64{}
+	for i, bound := range c.latencyBuckets {
+		buckets[bound] = c.latencyCounts[i]
+	}
+	return c.latencyCount, c.latencySum, buckets
+}
+
+//recordLatencies adds the requests done since the previous background scrape to the latency histogram and computes their quantiles.
+//Scrapes without requests keep the previous quantiles.
+func (c *ExporterClient) recordLatencies() {
+	if c.transport == nil {
+		return
+	}
+
+	latencies := c.transport.takeLatencies()
+	if len(latencies) == 0 {
+		return
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	c.mutex.Lock()
+	c.latencySeen = true
+	c.latencyP50 = quantile(latencies, 0.5)
+	c.latencyP95 = quantile(latencies, 0.95)
+	for _, latency := range latencies {
+		c.latencyCount++
+		c.latencySum += latency.Seconds()
+		for i, bound := range c.latencyBuckets {
+			if latency.Seconds() <= bound {
+				c.latencyCounts[i]++
+			}
+		}
+	}
+	c.mutex.Unlock()
+}
+
+//defaultLatencyBuckets are the default buckets for the API latency, ranging from 5ms to 10s.
+var defaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+//quantile returns the nearest-rank quantile of the sorted latencies.
+func quantile(sorted []time.Duration, q float64) time.Duration {
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+//fetchData runs the named background scrape and keeps track of genuine failures.
+func (c *ExporterClient) fetchData(name string, scrape func() error) {
+	err := scrape()
+	c.recordLatencies()
+	if err == nil {
+		c.mutex.Lock()
+		if c.lastScrapeErrBy == name {
+			c.lastScrapeErr = nil
+		}
+		onScrape := c.onScrape
+		c.mutex.Unlock()
+
+		if onScrape != nil {
+			onScrape()
+		}
+		return
+	}
+
+	// A scrape that fails because the client is stopped isn't a failure of Gitlab itself, requests to Gitlab that time out are.
+	if c.ctx.Err() != nil {
+		log.Debug("Scraping cancelled: ", err)
+		return
+	}
+
+	c.mutex.Lock()
+	c.scrapeFailures++
+	c.lastScrapeErr = classifyError(err)
+	c.lastScrapeErrBy = name
+	c.mutex.Unlock()
+
+	log.Error("Scraping failed: ", err)
+}
+
+//OnScrape sets a function that is called after every successful background scrape.
+func (c *ExporterClient) OnScrape(f func()) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.onScrape = f
+}
+
+//Stop stops the background scrapes, a scrape that is already running is finished.
+func (c *ExporterClient) Stop() {
+	c.cancel()
+}
+
 func (c *ExporterClient) startFetchData() {
 
-	// Do initial call to have data from the start.
+	// Do initial calls to have data from the start, the details need the listing first.
 	go func() {
-		err := c.getData()
-		if err != nil {
-			log.Error("Scraping failed.")
-		}
+		c.fetchData("listing", c.getData)
+		c.fetchData("details", c.getDetailData)
 	}()
 
-	ticker := time.NewTicker(c.interval * time.Second)
-	quit := make(chan struct{})
+	c.startTicker(c.interval*time.Second, "listing", c.getData)
+	c.startTicker(c.detailInterval*time.Second, "details", c.getDetailData)
+
+	c.startProjectRefreshes()
+}
+
+//startTicker runs the scrape on every interval until the client is stopped.
+func (c *ExporterClient) startTicker(interval time.Duration, name string, scrape func() error) {
+
+	ticker := time.NewTicker(interval)
 
 	go func() {
 		for {
 			select {
 			case <-ticker.C:
-				err := c.getData()
-				if err != nil {
-					log.Error("Scraping failed.")
-				}
-			case <-quit:
+				c.fetchData(name, scrape)
+			case <-c.ctx.Done():
 				ticker.Stop()
 				return
 			}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//CommitAuthorStats is the struct for the authors of commits on the default branch of a project.
+type CommitAuthorStats struct {
+	ProjectID string
+	Authors   []string
+}
+
+//getCommitAuthors retrieves the distinct commit authors of the last 7 days on the default branch of the projects.
+//Projects without a default branch or of which the repository isn't available are skipped.
+func getCommitAuthors(ctx context.Context, c *gitlab.Client, projects []ProjectStats) (*[]CommitAuthorStats, error) {
+
+	since := time.Now().Add(-Window)
+	results := make([]*CommitAuthorStats, len(projects))
+
+	err := forEach(len(projects), func(i int) error {
+		project := projects[i]
+		if project.DefaultBranch == "" {
+			return nil
+		}
+
+		authors := map[string]bool{}
+		page := 1
+
+		for {
+			commits, resp, err := c.Commits.ListCommits(project.ID, &gitlab.ListCommitsOptions{
+				ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
+				Since:       &since,
+			}, gitlab.WithContext(ctx))
+			if err != nil {
+				if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound) {
+					return nil
+				}
+				return err
+			}
+
+			for _, commit := range commits {
+				authors[commit.AuthorEmail] = true
+			}
+
+			if !hasNextPage(resp) {
+				break
+			}
+			page++
+		}
+
+		stats := &CommitAuthorStats{ProjectID: project.ID}
+		for author := range authors {
+			stats.Authors = append(stats.Authors, author)
+		}
+		results[i] = stats
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []CommitAuthorStats
+	for _, authors := range results {
+		if authors != nil {
+			result = append(result, *authors)
+		}
+	}
+
+	return &result, nil
+}
+
+//CommitCountStats is the struct for the amount of commits on the default branch of a project.
+type CommitCountStats struct {
+	ProjectID string
+	Commits   int
+}
+
+//getCommitCounts counts the commits of the last 7 days on the default branch of the projects.
+//Projects without a default branch or of which the repository isn't available are skipped.
+func getCommitCounts(ctx context.Context, c *gitlab.Client, projects []ProjectStats) (*[]CommitCountStats, error) {
+
+	since := time.Now().Add(-Window)
+	results := make([]*CommitCountStats, len(projects))
+
+	err := forEach(len(projects), func(i int) error {
+		project := projects[i]
+		if project.DefaultBranch == "" {
+			return nil
+		}
+
+		count := 0
+		page := 1
+
+		for {
+			commits, resp, err := c.Commits.ListCommits(project.ID, &gitlab.ListCommitsOptions{
+				ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
+				Since:       &since,
+			}, gitlab.WithContext(ctx))
+			if err != nil {
+				if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound) {
+					return nil
+				}
+				return err
+			}
+
+			count += len(commits)
+
+			if !hasNextPage(resp) {
+				break
+			}
+			page++
+		}
+
+		results[i] = &CommitCountStats{ProjectID: project.ID, Commits: count}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []CommitCountStats
+	for _, count := range results {
+		if count != nil {
+			result = append(result, *count)
+		}
+	}
+
+	return &result, nil
+}
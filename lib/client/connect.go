@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//connectRetryDelay is the delay before the first retry of connecting to Gitlab, it doubles on every next retry.
+const connectRetryDelay = time.Second
+
+//connect constructs the Gitlab client, retrying when Gitlab isn't reachable yet, e.g. when DNS isn't resolvable at startup.
+//Reachability is checked with the version endpoint, any response of Gitlab counts as reachable.
+//When Gitlab still isn't reachable after the retries the client is used anyway, so the scrapes report the errors.
+//Waiting for the next retry ends when the client is stopped.
+func (c *ExporterClient) connect(retries int) (*gitlab.Client, error) {
+
+	delay := connectRetryDelay
+
+	for attempt := 0; ; attempt++ {
+		glc, err := gitlab.NewClient(c.gitlabAPIKey, gitlab.WithBaseURL(c.gitlabURI), gitlab.WithHTTPClient(c.httpClient))
+		if err == nil {
+			var resp *gitlab.Response
+			resp, err = getVersion(c.ctx, glc)
+			if err == nil || resp != nil || attempt >= retries {
+				return glc, nil
+			}
+		} else if attempt >= retries {
+			return nil, err
+		}
+
+		log.Warn("Unable to connect to Gitlab, retrying in ", delay, ": ", err)
+		select {
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+//getVersion requests the version endpoint, the VersionService of go-gitlab doesn't accept a context.
+func getVersion(ctx context.Context, c *gitlab.Client) (*gitlab.Response, error) {
+	req, err := c.NewRequest(http.MethodGet, "version", nil, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req, nil)
+}
+
+//gitlabClient returns the Gitlab client constructed by New, or constructs it again when that failed.
+func (c *ExporterClient) gitlabClient() (*gitlab.Client, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.gitlab != nil {
+		return c.gitlab, nil
+	}
+
+	glc, err := gitlab.NewClient(c.gitlabAPIKey, gitlab.WithBaseURL(c.gitlabURI), gitlab.WithHTTPClient(c.httpClient))
+	if err != nil {
+		return nil, err
+	}
+	c.gitlab = glc
+
+	return glc, nil
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//ForcePushStats is the struct for whether the source branch of an approved MR was force-pushed after the last approval.
+type ForcePushStats struct {
+	ID            string
+	ProjectID     string
+	AfterApproval bool
+}
+
+//getForcePushes checks the open MRs that were approved for a force-push after the last approval.
+//The diff version at the moment of approval is compared with the latest one, when its head isn't an ancestor of the latest head the history was rewritten.
+//MRs without approvals are skipped.
+func getForcePushes(ctx context.Context, c *gitlab.Client, mergeStats []MergeRequestStats) (*[]ForcePushStats, error) {
+
+	results := make([]*ForcePushStats, len(mergeStats))
+
+	err := forEach(len(mergeStats), func(i int) error {
+		mr := mergeStats[i]
+
+		approval, err := getLastApproval(ctx, c, mr)
+		if err != nil || approval == nil {
+			return err
+		}
+
+		versions, err := getDiffVersions(ctx, c, mr)
+		if err != nil {
+			return err
+		}
+
+		var approved *gitlab.MergeRequestDiffVersion
+		for _, version := range versions {
+			if version.CreatedAt != nil && !version.CreatedAt.After(*approval.CreatedAt) {
+				approved = version
+			}
+		}
+		if approved == nil {
+			return nil
+		}
+
+		stats := &ForcePushStats{
+			ID:        mr.ID,
+			ProjectID: mr.ProjectID,
+		}
+
+		latest := versions[len(versions)-1]
+		if latest.HeadCommitSHA != approved.HeadCommitSHA {
+			base, _, err := c.Repositories.MergeBase(mr.ProjectID, &gitlab.MergeBaseOptions{
+				Ref: []string{approved.HeadCommitSHA, latest.HeadCommitSHA},
+			}, gitlab.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+			stats.AfterApproval = base.ID != approved.HeadCommitSHA
+		}
+
+		results[i] = stats
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ForcePushStats
+	for _, forcePush := range results {
+		if forcePush != nil {
+			result = append(result, *forcePush)
+		}
+	}
+
+	return &result, nil
+}
+
+//getLastApproval returns the last system note of the MR that approves it, if there is one.
+func getLastApproval(ctx context.Context, c *gitlab.Client, mr MergeRequestStats) (*gitlab.Note, error) {
+
+	page := 1
+
+	for {
+		notes, resp, err := c.Notes.ListMergeRequestNotes(mr.ProjectID, mr.InternalID, &gitlab.ListMergeRequestNotesOptions{
+			ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
+			OrderBy:     gitlab.String("created_at"),
+			Sort:        gitlab.String("desc"),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, note := range notes {
+			if note.System && note.CreatedAt != nil && strings.HasPrefix(note.Body, "approved this merge request") {
+				return note, nil
+			}
+		}
+
+		if !hasNextPage(resp) {
+			return nil, nil
+		}
+		page++
+	}
+}
+
+//getDiffVersions retrieves all diff versions of the MR, oldest first.
+func getDiffVersions(ctx context.Context, c *gitlab.Client, mr MergeRequestStats) ([]*gitlab.MergeRequestDiffVersion, error) {
+
+	var result []*gitlab.MergeRequestDiffVersion
+
+	page := 1
+
+	for {
+		versions, resp, err := c.MergeRequests.GetMergeRequestDiffVersions(mr.ProjectID, mr.InternalID, &gitlab.GetMergeRequestDiffVersionsOptions{Page: page, PerPage: 100}, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, versions...)
+		if !hasNextPage(resp) {
+			break
+		}
+		page++
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result, nil
+}
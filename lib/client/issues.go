@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//IssueStats is the struct for an open issue of a project.
+type IssueStats struct {
+	ID        string
+	ProjectID string
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+}
+
+//getIssues retrieves the open issues of the projects.
+//Projects of which the issues aren't available, e.g. because issues are disabled, are taken as having no issues.
+func getIssues(ctx context.Context, c *gitlab.Client, projects []ProjectStats) (*[]IssueStats, error) {
+
+	results := make([][]IssueStats, len(projects))
+
+	err := forEach(len(projects), func(i int) error {
+		project := projects[i]
+		page := 1
+
+		for {
+			issues, resp, err := c.Issues.ListProjectIssues(project.ID, &gitlab.ListProjectIssuesOptions{
+				ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
+				State:       gitlab.String("opened"),
+			}, gitlab.WithContext(ctx))
+			if err != nil {
+				if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound) {
+					results[i] = nil
+					return nil
+				}
+				return err
+			}
+
+			for _, issue := range issues {
+				results[i] = append(results[i], IssueStats{
+					ID:        strconv.Itoa(issue.ID),
+					ProjectID: project.ID,
+					CreatedAt: issue.CreatedAt,
+					UpdatedAt: issue.UpdatedAt,
+				})
+			}
+
+			if !hasNextPage(resp) {
+				break
+			}
+			page++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []IssueStats
+	for _, issues := range results {
+		result = append(result, issues...)
+	}
+
+	return &result, nil
+}
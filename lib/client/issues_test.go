@@ -0,0 +1,27 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGetIssuesSkipsDisabledIssues(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/1/issues", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 10, "created_at": "2021-01-01T00:00:00Z"}]`)
+	})
+	mux.HandleFunc("/api/v4/projects/2/issues", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "403 Forbidden"}`, http.StatusForbidden)
+	})
+	c := newTestClient(t, mux)
+
+	issues, err := getIssues(context.Background(), c, []ProjectStats{{ID: "1"}, {ID: "2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*issues) != 1 || (*issues)[0].ProjectID != "1" {
+		t.Errorf("expected only the issue of project 1, got %+v", *issues)
+	}
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//LabelEventStats is the struct for the amount of times a tracked label was added to and removed from a MR.
+type LabelEventStats struct {
+	ID        string
+	ProjectID string
+	Label     string
+	Added     int
+	Removed   int
+}
+
+//getLabelEvents counts how many times each of the tracked labels was added to and removed from the given MRs.
+func getLabelEvents(ctx context.Context, c *gitlab.Client, mergeStats []MergeRequestStats, labels []string) (*[]LabelEventStats, error) {
+
+	results := make([][]LabelEventStats, len(mergeStats))
+
+	err := forEach(len(mergeStats), func(i int) error {
+		mr := mergeStats[i]
+
+		added := map[string]int{}
+		removed := map[string]int{}
+		for _, label := range labels {
+			added[label] = 0
+		}
+
+		page := 1
+
+		for {
+			events, resp, err := c.ResourceLabelEvents.ListMergeLabelEvents(mr.ProjectID, mr.InternalID, &gitlab.ListLabelEventsOptions{
+				ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
+			}, gitlab.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+
+			for _, event := range events {
+				if _, ok := added[event.Label.Name]; !ok {
+					continue
+				}
+				switch event.Action {
+				case "add":
+					added[event.Label.Name]++
+				case "remove":
+					removed[event.Label.Name]++
+				}
+			}
+
+			if !hasNextPage(resp) {
+				break
+			}
+			page++
+		}
+
+		for _, label := range labels {
+			results[i] = append(results[i], LabelEventStats{
+				ID:        mr.ID,
+				ProjectID: mr.ProjectID,
+				Label:     label,
+				Added:     added[label],
+				Removed:   removed[label],
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []LabelEventStats
+	for _, events := range results {
+		result = append(result, events...)
+	}
+
+	return &result, nil
+}
@@ -1,6 +1,13 @@
 package client
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -19,9 +26,11 @@ type MergeClosedStats struct {
 
 //MergeMergedStats is the strucct for merged merge requests
 type MergeMergedStats struct {
-	MergeRequest MergeRequestStats
-	MergedAt     *time.Time
-	Duration     float64
+	MergeRequest   MergeRequestStats
+	MergedAt       *time.Time
+	Duration       float64
+	PipelineStatus string
+	MergedBy       string
 }
 
 //MergeRequestStats is the base struct for Gitlab Merge Requests data we want
@@ -33,10 +42,18 @@ type MergeRequestStats struct {
 	SourceBranch string
 	ProjectID    string
 	ChangeCount  string
-	Title        string
-	LastUpdated  *time.Time
-	CreatedAt    *time.Time
-	Assignees    int
+
+	//SourceProjectID differs from the ProjectID for MRs from forks.
+	SourceProjectID string
+
+	Title       string
+	LastUpdated *time.Time
+	CreatedAt   *time.Time
+	Assignees   int
+	Reviewers   int
+	Author      string
+
+	RebaseInProgress bool
 }
 
 //ApprovalStats is the struct for Gitlab Approvals data we want
@@ -44,6 +61,20 @@ type ApprovalStats struct {
 	Approvals int
 	ID        string
 	ProjectID string
+
+	Rules []ApprovalRuleStats
+
+	//AwaitingUser is whether the user of the token is an eligible approver of a rule that isn't approved yet.
+	AwaitingUser bool
+}
+
+//ApprovalRuleStats is the struct for an approval rule that applies to a MR.
+type ApprovalRuleStats struct {
+	Name              string
+	Type              string
+	ApprovalsRequired int
+	ApprovalsGiven    int
+	Approved          bool
 }
 
 //ChangeStats is the struct for the total amount of changes within a MR.
@@ -52,12 +83,153 @@ type ChangeStats struct {
 	ID        string
 	Additions int
 	Deletions int
+
+	//DiffBytes is the size of the diffs, which shows large changes on few lines, e.g. to generated files.
+	DiffBytes int
+
+	Extensions []ExtensionChangeStats
+}
+
+//ExtensionChangeStats is the struct for the changes within a MR to files of a tracked extension.
+type ExtensionChangeStats struct {
+	Extension string
+	Additions int
+	Deletions int
+}
+
+//UpdateStats is the struct for the amount of updates seen on a MR across scrapes.
+type UpdateStats struct {
+	ID        string
+	ProjectID string
+	Updates   int
+}
+
+//Window is the period in which merge requests need to be updated to be retrieved.
+const Window = 7 * 24 * time.Hour
+
+//mergeRequestDetail is a Gitlab merge request with the fields go-gitlab doesn't support yet.
+type mergeRequestDetail struct {
+	gitlab.MergeRequest
+	Reviewers []*gitlab.BasicUser `json:"reviewers"`
+}
+
+//getMergeRequestDetail retrieves a single merge request including its reviewers and rebase state.
+func getMergeRequestDetail(ctx context.Context, c *gitlab.Client, projectID string, internalID int) (*mergeRequestDetail, error) {
+	opt := &gitlab.GetMergeRequestsOptions{IncludeRebaseInProgress: gitlab.Bool(true)}
+
+	req, err := c.NewRequest(http.MethodGet, fmt.Sprintf("projects/%s/merge_requests/%d", url.PathEscape(projectID), internalID), opt, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(mergeRequestDetail)
+	if _, err := c.Do(req, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+//listMergeRequestsOptions returns the options used to list the merge requests of the last 7 days.
+func (c *ExporterClient) listMergeRequestsOptions() gitlab.ListMergeRequestsOptions {
+
+	windowStart := time.Now().Add(-Window)
+
+	opt := gitlab.ListMergeRequestsOptions{
+		Scope: gitlab.String(c.mrScope),
+		WIP:   gitlab.String("no"),
+	}
+
+	if c.targetBranch != "" {
+		opt.TargetBranch = gitlab.String(c.targetBranch)
+	}
+
+	// Every MR created within the window was also updated within it, so the created filter replaces the updated one.
+	if c.windowBy == "created_at" {
+		opt.CreatedAfter = &windowStart
+	} else {
+		opt.UpdatedAfter = &windowStart
+	}
+
+	if c.milestone != "" {
+		opt.Milestone = gitlab.String(c.milestone)
+	}
+	if c.mrOrderBy != "" {
+		opt.OrderBy = gitlab.String(c.mrOrderBy)
+	}
+	if c.mrSort != "" {
+		opt.Sort = gitlab.String(c.mrSort)
+	}
+
+	return opt
+}
+
+//FilteredStats is the struct for the amount of merge requests left out by a filter.
+type FilteredStats struct {
+	Reason string
+	Count  int
+}
+
+//getFilteredCounts compares the total amount of merge requests reported by Gitlab with and without each of the filters.
+//Filters of which Gitlab doesn't report totals (above 10.000 results) are skipped.
+func (c *ExporterClient) getFilteredCounts(glc *gitlab.Client) (*[]FilteredStats, error) {
+
+	base := c.listMergeRequestsOptions()
+
+	count := func(opt gitlab.ListMergeRequestsOptions) (int, bool, error) {
+		opt.ListOptions = gitlab.ListOptions{Page: 1, PerPage: 1}
+
+		_, resp, err := glc.MergeRequests.ListMergeRequests(&opt, gitlab.WithContext(c.ctx))
+		if err != nil {
+			return 0, false, err
+		}
+		if resp.Header.Get("X-Total") == "" {
+			return 0, false, nil
+		}
+		return resp.TotalItems, true, nil
+	}
+
+	total, ok, err := count(base)
+	if err != nil || !ok {
+		return &[]FilteredStats{}, err
+	}
+
+	var result []FilteredStats
+
+	drafts := base
+	drafts.WIP = gitlab.String("yes")
+	if filtered, ok, err := count(drafts); err != nil {
+		return nil, err
+	} else if ok {
+		result = append(result, FilteredStats{Reason: "draft", Count: filtered})
+	}
+
+	if base.TargetBranch != nil {
+		branches := base
+		branches.TargetBranch = nil
+		if unfiltered, ok, err := count(branches); err != nil {
+			return nil, err
+		} else if ok {
+			result = append(result, FilteredStats{Reason: "branch", Count: unfiltered - total})
+		}
+	}
+
+	if base.Milestone != nil {
+		milestones := base
+		milestones.Milestone = nil
+		if unfiltered, ok, err := count(milestones); err != nil {
+			return nil, err
+		} else if ok {
+			result = append(result, FilteredStats{Reason: "milestone", Count: unfiltered - total})
+		}
+	}
+
+	return &result, nil
 }
 
 //getMergeRequest retrieves all merge requests of the last 7 days
-func getMergeRequest(c *gitlab.Client) (*[]MergeRequestStats, error) {
+func getMergeRequest(ctx context.Context, c *gitlab.Client, opt gitlab.ListMergeRequestsOptions) (*[]MergeRequestStats, error) {
 
-	updateAfter := time.Now().Add(-7 * 24 * time.Hour)
 	var result []MergeRequestStats
 
 	var mrTotal []*gitlab.MergeRequest
@@ -65,44 +237,132 @@ func getMergeRequest(c *gitlab.Client) (*[]MergeRequestStats, error) {
 	page := 1
 
 	for {
-		mr, _, err := c.MergeRequests.ListMergeRequests(&gitlab.ListMergeRequestsOptions{
-			ListOptions:  gitlab.ListOptions{Page: page, PerPage: 100},
-			UpdatedAfter: &updateAfter,
-			TargetBranch: gitlab.String("master"),
-			Scope:        gitlab.String("all"),
-			WIP:          gitlab.String("no"),
-		})
+		opt.ListOptions = gitlab.ListOptions{Page: page, PerPage: 100}
+
+		mr, resp, err := c.MergeRequests.ListMergeRequests(&opt, gitlab.WithContext(ctx))
 		if err != nil {
 			return nil, err
 		}
 
-		if len(mr) == 0 {
+		mrTotal = append(mrTotal, mr...)
+		if !hasNextPage(resp) {
 			break
 		}
-
-		mrTotal = append(mrTotal, mr...)
 		page++
 	}
 
-	log.Info("Found a total of: ", len(mrTotal), " MRs")
+	log.Debug("Found a total of: ", len(mrTotal), " MRs")
 
 	for _, mr := range mrTotal {
-		result = append(result, MergeRequestStats{
-			ProjectID:    strconv.Itoa(mr.ProjectID),
-			State:        mr.State,
-			TargetBranch: mr.TargetBranch,
-			SourceBranch: mr.SourceBranch,
-			Title:        mr.Title,
-			ID:           strconv.Itoa(mr.ID),
-			InternalID:   mr.IID,
-		})
+		result = append(result, listedMergeRequest(mr))
+	}
+
+	return &result, nil
+}
+
+//getMemberMergeRequests retrieves the MRs of the given projects one project at a time, instead of listing all MRs visible to the token.
+func getMemberMergeRequests(ctx context.Context, c *gitlab.Client, projects []ProjectStats, opt gitlab.ListMergeRequestsOptions) (*[]MergeRequestStats, error) {
+
+	results := make([][]MergeRequestStats, len(projects))
+
+	err := forEach(len(projects), func(i int) error {
+		mrs, err := getProjectMergeRequests(ctx, c, projects[i].ID, opt)
+		if err != nil {
+			return err
+		}
+		results[i] = *mrs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []MergeRequestStats
+	for _, mrs := range results {
+		result = append(result, mrs...)
+	}
+
+	log.Debug("Found a total of: ", len(result), " MRs in the member projects")
+
+	return &result, nil
+}
+
+//getProjectMergeRequests retrieves the MRs of a single project with the same filters as the listing of all MRs.
+func getProjectMergeRequests(ctx context.Context, c *gitlab.Client, pid string, opt gitlab.ListMergeRequestsOptions) (*[]MergeRequestStats, error) {
+
+	var result []MergeRequestStats
+
+	projectOpt := gitlab.ListProjectMergeRequestsOptions{
+		TargetBranch: opt.TargetBranch,
+		Scope:        opt.Scope,
+		WIP:          opt.WIP,
+		CreatedAfter: opt.CreatedAfter,
+		UpdatedAfter: opt.UpdatedAfter,
+		Milestone:    opt.Milestone,
+		OrderBy:      opt.OrderBy,
+		Sort:         opt.Sort,
+	}
+
+	page := 1
+
+	for {
+		projectOpt.ListOptions = gitlab.ListOptions{Page: page, PerPage: 100}
+
+		mrs, resp, err := c.MergeRequests.ListProjectMergeRequests(pid, &projectOpt, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, mr := range mrs {
+			result = append(result, listedMergeRequest(mr))
+		}
+		if !hasNextPage(resp) {
+			break
+		}
+		page++
 	}
 
 	return &result, nil
 }
 
+func listedMergeRequest(mr *gitlab.MergeRequest) MergeRequestStats {
+	return MergeRequestStats{
+		ProjectID: strconv.Itoa(mr.ProjectID),
+		State:     mr.State,
+
+		SourceProjectID: strconv.Itoa(mr.SourceProjectID),
+
+		TargetBranch: mr.TargetBranch,
+		SourceBranch: mr.SourceBranch,
+		Title:        mr.Title,
+		ID:           strconv.Itoa(mr.ID),
+		InternalID:   mr.IID,
+		LastUpdated:  mr.UpdatedAt,
+		Author:       username(mr.Author),
+	}
+}
+
+//limitMergeRequests returns the max most recently updated MRs, and whether MRs were left out. A max of 0 means no limit.
+func limitMergeRequests(mrs []MergeRequestStats, max int) ([]MergeRequestStats, bool) {
+	if max <= 0 || len(mrs) <= max {
+		return mrs, false
+	}
+
+	sorted := append([]MergeRequestStats{}, mrs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].LastUpdated == nil || sorted[j].LastUpdated == nil {
+			return sorted[j].LastUpdated == nil && sorted[i].LastUpdated != nil
+		}
+		return sorted[i].LastUpdated.After(*sorted[j].LastUpdated)
+	})
+
+	return sorted[:max], true
+}
+
 //getMergeRequestsDetails retrieves the details of given MRs we need for metrics.
-func getMergeRequestsDetails(c *gitlab.Client, mrs []MergeRequestStats) (*[]MergeRequestStats, *[]MergeMergedStats, *[]MergeClosedStats, error) {
+//Open MRs of which Gitlab is still computing the changes are retrieved once more after the retryDelay, a retryDelay of 0 doesn't retry.
+//Merged and closed MRs with a merge error are left out, the amount of them per state is returned as well.
+func getMergeRequestsDetails(ctx context.Context, c *gitlab.Client, mrs []MergeRequestStats, retryDelay time.Duration) (*[]MergeRequestStats, *[]MergeMergedStats, *[]MergeClosedStats, map[string]int, error) {
 
 	var mrOpen []MergeRequestStats
 	var resultOpen *[]MergeRequestStats
@@ -113,6 +373,8 @@ func getMergeRequestsDetails(c *gitlab.Client, mrs []MergeRequestStats) (*[]Merg
 	var mrClosed []MergeRequestStats
 	var resultClosed *[]MergeClosedStats
 
+	var skippedMerged, skippedClosed int
+
 	for _, mr := range mrs {
 		switch {
 		case mr.State == "opened":
@@ -126,182 +388,703 @@ func getMergeRequestsDetails(c *gitlab.Client, mrs []MergeRequestStats) (*[]Merg
 
 	var wg sync.WaitGroup
 
-	errCh := make(chan error, 1)
+	// Every goroutine can send an error, so none of them blocks on it.
+	errCh := make(chan error, 3)
 
 	wg.Add(3)
 
 	go func() {
-		resultOpen = getOpenMergeRequests(c, errCh, &wg, mrOpen)
+		resultOpen = getOpenMergeRequests(ctx, c, errCh, &wg, mrOpen, retryDelay)
 	}()
 
 	go func() {
-		resultMerged = getMergedMergeRequests(c, errCh, &wg, mrMerged)
+		resultMerged, skippedMerged = getMergedMergeRequests(ctx, c, errCh, &wg, mrMerged)
 	}()
 
 	go func() {
-		resultClosed = getClosedMergeRequests(c, errCh, &wg, mrClosed)
+		resultClosed, skippedClosed = getClosedMergeRequests(ctx, c, errCh, &wg, mrClosed)
 	}()
 
 	wg.Wait()
 	close(errCh)
 	for err := range errCh {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
-	return resultOpen, resultMerged, resultClosed, nil
+	return resultOpen, resultMerged, resultClosed, map[string]int{"merged": skippedMerged, "closed": skippedClosed}, nil
 }
 
-func getOpenMergeRequests(c *gitlab.Client, errCh chan<- error, wg *sync.WaitGroup, mergeStats []MergeRequestStats) *[]MergeRequestStats {
+func getOpenMergeRequests(ctx context.Context, c *gitlab.Client, errCh chan<- error, wg *sync.WaitGroup, mergeStats []MergeRequestStats, retryDelay time.Duration) *[]MergeRequestStats {
+	defer wg.Done()
 
 	var resultOpen []MergeRequestStats
 
-	for _, mr := range mergeStats {
+	details := make([]*mergeRequestDetail, len(mergeStats))
+	var pending []int
+
+	for i, mr := range mergeStats {
 
-		result, _, err := c.MergeRequests.GetMergeRequest(mr.ProjectID, mr.InternalID, &gitlab.GetMergeRequestsOptions{})
+		result, err := getMergeRequestDetail(ctx, c, mr.ProjectID, mr.InternalID)
 		if err != nil {
 			errCh <- err
 			return nil
 		}
+		details[i] = result
+
+		// The changes count is empty while Gitlab is still computing the diff.
+		if result.ChangesCount == "" {
+			pending = append(pending, i)
+		}
+	}
+
+	// The pending MRs are retrieved once more after waiting once for all of them.
+	if len(pending) > 0 && retryDelay > 0 {
+		select {
+		case <-ctx.Done():
+			errCh <- ctx.Err()
+			return nil
+		case <-time.After(retryDelay):
+		}
+
+		for _, i := range pending {
+			result, err := getMergeRequestDetail(ctx, c, mergeStats[i].ProjectID, mergeStats[i].InternalID)
+			if err != nil {
+				errCh <- err
+				return nil
+			}
+			details[i] = result
+		}
+	}
+
+	for _, result := range details {
 
 		resultOpen = append(resultOpen, MergeRequestStats{
-			ProjectID:    strconv.Itoa(result.ProjectID),
-			ID:           strconv.Itoa(result.ID),
-			InternalID:   result.IID,
-			CreatedAt:    result.CreatedAt,
-			LastUpdated:  result.UpdatedAt,
-			ChangeCount:  result.ChangesCount,
-			Assignees:    len(result.Assignees),
-			SourceBranch: result.SourceBranch,
+			ProjectID:       strconv.Itoa(result.ProjectID),
+			SourceProjectID: strconv.Itoa(result.SourceProjectID),
+			ID:              strconv.Itoa(result.ID),
+			InternalID:      result.IID,
+			CreatedAt:       result.CreatedAt,
+			LastUpdated:     result.UpdatedAt,
+			ChangeCount:     result.ChangesCount,
+			Assignees:       len(result.Assignees),
+			Reviewers:       len(result.Reviewers),
+			SourceBranch:    result.SourceBranch,
+			TargetBranch:    result.TargetBranch,
+			Title:           result.Title,
+			Author:          username(result.Author),
+
+			RebaseInProgress: result.RebaseInProgress,
 		})
 
 	}
-	log.Info(len(resultOpen), " Open MRs")
-	wg.Done()
+	log.Debug(len(resultOpen), " Open MRs")
 
 	return &resultOpen
 }
 
-func getMergedMergeRequests(c *gitlab.Client, errCh chan<- error, wg *sync.WaitGroup, mergeStats []MergeRequestStats) *[]MergeMergedStats {
+func getMergedMergeRequests(ctx context.Context, c *gitlab.Client, errCh chan<- error, wg *sync.WaitGroup, mergeStats []MergeRequestStats) (*[]MergeMergedStats, int) {
+	defer wg.Done()
 
 	var resultMerged []MergeMergedStats
+	skipped := 0
 
 	for _, mr := range mergeStats {
 
-		result, _, err := c.MergeRequests.GetMergeRequest(mr.ProjectID, mr.InternalID, &gitlab.GetMergeRequestsOptions{})
+		result, err := getMergeRequestDetail(ctx, c, mr.ProjectID, mr.InternalID)
 		if err != nil {
 			errCh <- err
-			return nil
+			return nil, 0
 		}
 
-		if result.MergeError == "" {
+		if result.MergeError != "" {
+			log.Debug("Skipping merged MR ", mr.ID, " with merge error: ", result.MergeError)
+			skipped++
+		} else {
 			duration, _ := time.ParseDuration(result.MergedAt.Sub(*result.CreatedAt).String())
 
 			resultMerged = append(resultMerged, MergeMergedStats{
-				MergedAt: result.MergedAt,
-				Duration: duration.Seconds(),
+				MergedAt:       result.MergedAt,
+				Duration:       duration.Seconds(),
+				PipelineStatus: pipelineStatus(&result.MergeRequest),
+				MergedBy:       username(result.MergedBy),
 				MergeRequest: MergeRequestStats{
-					ProjectID:    strconv.Itoa(result.ProjectID),
-					ID:           strconv.Itoa(result.ID),
-					CreatedAt:    result.CreatedAt,
-					LastUpdated:  result.UpdatedAt,
-					ChangeCount:  result.ChangesCount,
-					Assignees:    len(result.Assignees),
-					SourceBranch: result.SourceBranch,
+					ProjectID:       strconv.Itoa(result.ProjectID),
+					SourceProjectID: strconv.Itoa(result.SourceProjectID),
+					ID:              strconv.Itoa(result.ID),
+					InternalID:      result.IID,
+					CreatedAt:       result.CreatedAt,
+					LastUpdated:     result.UpdatedAt,
+					ChangeCount:     result.ChangesCount,
+					Assignees:       len(result.Assignees),
+					Reviewers:       len(result.Reviewers),
+					SourceBranch:    result.SourceBranch,
+					Author:          username(result.Author),
 				},
 			})
 		}
 	}
-	log.Info(len(resultMerged), " Merged MRs")
-	wg.Done()
+	log.Debug(len(resultMerged), " Merged MRs")
 
-	return &resultMerged
+	return &resultMerged, skipped
 }
 
-func getClosedMergeRequests(c *gitlab.Client, errCh chan<- error, wg *sync.WaitGroup, mergeStats []MergeRequestStats) *[]MergeClosedStats {
+func getClosedMergeRequests(ctx context.Context, c *gitlab.Client, errCh chan<- error, wg *sync.WaitGroup, mergeStats []MergeRequestStats) (*[]MergeClosedStats, int) {
+	defer wg.Done()
 
 	var resultClosed []MergeClosedStats
+	skipped := 0
 
 	for _, mr := range mergeStats {
 
-		result, _, err := c.MergeRequests.GetMergeRequest(mr.ProjectID, mr.InternalID, &gitlab.GetMergeRequestsOptions{})
+		result, err := getMergeRequestDetail(ctx, c, mr.ProjectID, mr.InternalID)
 		if err != nil {
 			errCh <- err
-			return nil
+			return nil, 0
 		}
 
-		if result.MergeError == "" {
+		if result.MergeError != "" {
+			log.Debug("Skipping closed MR ", mr.ID, " with merge error: ", result.MergeError)
+			skipped++
+		} else {
 			duration, _ := time.ParseDuration(result.ClosedAt.Sub(*result.CreatedAt).String())
 
 			resultClosed = append(resultClosed, MergeClosedStats{
 				ClosedAt: result.ClosedAt,
 				Duration: duration.Seconds(),
 				MergeRequest: MergeRequestStats{
-					ProjectID:    strconv.Itoa(result.ProjectID),
-					ID:           strconv.Itoa(result.ID),
-					CreatedAt:    result.CreatedAt,
-					LastUpdated:  result.UpdatedAt,
-					ChangeCount:  result.ChangesCount,
-					Assignees:    len(result.Assignees),
-					SourceBranch: result.SourceBranch,
+					ProjectID:       strconv.Itoa(result.ProjectID),
+					SourceProjectID: strconv.Itoa(result.SourceProjectID),
+					ID:              strconv.Itoa(result.ID),
+					InternalID:      result.IID,
+					CreatedAt:       result.CreatedAt,
+					LastUpdated:     result.UpdatedAt,
+					ChangeCount:     result.ChangesCount,
+					Assignees:       len(result.Assignees),
+					Reviewers:       len(result.Reviewers),
+					SourceBranch:    result.SourceBranch,
 				},
 			})
 		}
 
 	}
-	log.Info(len(resultClosed), " Closed MRs")
-	wg.Done()
+	log.Debug(len(resultClosed), " Closed MRs")
 
-	return &resultClosed
+	return &resultClosed, skipped
 }
 
-// getApprovals retrieves the amount of approvals left for a merge request
-func getApprovals(c *gitlab.Client, mergeStats []MergeRequestStats) (*[]ApprovalStats, error) {
+//errApprovalsUnavailable is returned when the Gitlab instance doesn't support merge request approvals, e.g. on Gitlab CE.
+var errApprovalsUnavailable = errors.New("merge request approvals are not available on this Gitlab instance")
+
+//getAvailableApprovals retrieves the approvals, unless an earlier scrape found the approvals feature to be unavailable.
+//Until an approvals request succeeded, a 403 or 404 on the first request means the feature is unavailable.
+func (c *ExporterClient) getAvailableApprovals(glc *gitlab.Client, mergeStats []MergeRequestStats, withRules bool) (*[]ApprovalStats, error) {
+
+	c.mutex.Lock()
+	unavailable := c.approvalsUnavailable
+	probe := !c.approvalsAvailable
+	c.mutex.Unlock()
+
+	if unavailable {
+		return &[]ApprovalStats{}, nil
+	}
+
+	userID := 0
+	if withRules {
+		userID = c.getCurrentUserID(glc)
+	}
+
+	approvals, err := getApprovals(c.ctx, glc, mergeStats, withRules, userID, probe)
+	if errors.Is(err, errApprovalsUnavailable) {
+		log.Warn("Merge request approvals are not available, disabling approval metrics")
+
+		c.mutex.Lock()
+		c.approvalsUnavailable = true
+		c.mutex.Unlock()
+
+		return &[]ApprovalStats{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*approvals) > 0 {
+		c.mutex.Lock()
+		c.approvalsAvailable = true
+		c.mutex.Unlock()
+	}
+
+	return approvals, nil
+}
+
+//withoutApproved leaves the fully approved open MRs out of the stats of open MRs, and returns the amount that was left out.
+//Open MRs without known approvals, e.g. when approvals aren't available, are kept.
+func withoutApproved(stats Stats) (Stats, int) {
+
+	approved := map[string]bool{}
+	resultApprovals := []ApprovalStats{}
+	for _, approval := range *stats.Approvals {
+		if approval.Approvals == 0 {
+			approved[approval.ID] = true
+			continue
+		}
+		resultApprovals = append(resultApprovals, approval)
+	}
+	stats.Approvals = &resultApprovals
+
+	resultMRs := []MergeRequestStats{}
+	for _, mr := range *stats.MergeRequests {
+		if mr.State != "opened" || !approved[mr.ID] {
+			resultMRs = append(resultMRs, mr)
+		}
+	}
+	stats.MergeRequests = &resultMRs
+
+	resultOpen := []MergeRequestStats{}
+	for _, mr := range *stats.MergeRequestsOpen {
+		if !approved[mr.ID] {
+			resultOpen = append(resultOpen, mr)
+		}
+	}
+	stats.MergeRequestsOpen = &resultOpen
+
+	resultUpdates := []UpdateStats{}
+	for _, update := range *stats.Updates {
+		if !approved[update.ID] {
+			resultUpdates = append(resultUpdates, update)
+		}
+	}
+	stats.Updates = &resultUpdates
+
+	resultChanges := []ChangeStats{}
+	for _, change := range *stats.Changes {
+		if !approved[change.ID] {
+			resultChanges = append(resultChanges, change)
+		}
+	}
+	stats.Changes = &resultChanges
+
+	resultPickups := []PickupStats{}
+	for _, pickup := range *stats.Pickups {
+		if !approved[pickup.ID] {
+			resultPickups = append(resultPickups, pickup)
+		}
+	}
+	stats.Pickups = &resultPickups
+
+	resultLabelEvents := []LabelEventStats{}
+	for _, event := range *stats.LabelEvents {
+		if !approved[event.ID] {
+			resultLabelEvents = append(resultLabelEvents, event)
+		}
+	}
+	stats.LabelEvents = &resultLabelEvents
+
+	resultChangesRequested := []ChangesRequestedStats{}
+	for _, requested := range *stats.ChangesRequested {
+		if !approved[requested.ID] {
+			resultChangesRequested = append(resultChangesRequested, requested)
+		}
+	}
+	stats.ChangesRequested = &resultChangesRequested
+
+	resultReviewRounds := []ReviewRoundStats{}
+	for _, rounds := range *stats.ReviewRounds {
+		if !approved[rounds.ID] {
+			resultReviewRounds = append(resultReviewRounds, rounds)
+		}
+	}
+	stats.ReviewRounds = &resultReviewRounds
+
+	resultDiscussions := []DiscussionStats{}
+	for _, discussions := range *stats.Discussions {
+		if !approved[discussions.ID] {
+			resultDiscussions = append(resultDiscussions, discussions)
+		}
+	}
+	stats.Discussions = &resultDiscussions
+
+	resultForcePushes := []ForcePushStats{}
+	for _, forcePush := range *stats.ForcePushes {
+		if !approved[forcePush.ID] {
+			resultForcePushes = append(resultForcePushes, forcePush)
+		}
+	}
+	stats.ForcePushes = &resultForcePushes
+
+	resultPipelineRetries := []PipelineRetryStats{}
+	for _, retries := range *stats.PipelineRetries {
+		if !approved[retries.ID] {
+			resultPipelineRetries = append(resultPipelineRetries, retries)
+		}
+	}
+	stats.PipelineRetries = &resultPipelineRetries
+
+	return stats, len(approved)
+}
+
+// getApprovals retrieves the amount of approvals left for a merge request, and the approval rules when withRules is set
+// With the rules it is also checked whether the approval of the given user is awaited, a userID of 0 skips this check
+// MRs of which the approvals aren't accessible, e.g. because the MR was deleted in the meantime, are skipped.
+// With probe a 403 or 404 before any approvals were retrieved is taken as the approvals feature being unavailable.
+func getApprovals(ctx context.Context, c *gitlab.Client, mergeStats []MergeRequestStats, withRules bool, userID int, probe bool) (*[]ApprovalStats, error) {
 	var result []ApprovalStats
 
 	for _, mr := range mergeStats {
-		approvals, _, err := c.MergeRequestApprovals.GetConfiguration(mr.ProjectID, mr.InternalID)
+		approvals, resp, err := c.MergeRequestApprovals.GetConfiguration(mr.ProjectID, mr.InternalID, gitlab.WithContext(ctx))
 		if err != nil {
+			if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound) {
+				if probe && len(result) == 0 {
+					return nil, errApprovalsUnavailable
+				}
+				log.Debug("Skipping the approvals of MR ", mr.ID, ", they aren't accessible: ", err)
+				continue
+			}
 			return nil, err
 		}
 
-		result = append(result, ApprovalStats{
+		stats := ApprovalStats{
 			Approvals: approvals.ApprovalsLeft,
 			ID:        mr.ID,
 			ProjectID: mr.ProjectID,
-		})
+		}
+
+		if withRules {
+			state, resp, err := c.MergeRequestApprovals.GetApprovalState(mr.ProjectID, mr.InternalID, gitlab.WithContext(ctx))
+			if err != nil {
+				if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound) {
+					if probe && len(result) == 0 {
+						return nil, errApprovalsUnavailable
+					}
+					log.Debug("Skipping the approvals of MR ", mr.ID, ", they aren't accessible: ", err)
+					continue
+				}
+				return nil, err
+			}
+
+			for _, rule := range state.Rules {
+				if userID != 0 && !rule.Approved && containsUser(rule.EligibleApprovers, userID) && !containsUser(rule.ApprovedBy, userID) {
+					stats.AwaitingUser = true
+				}
+
+				stats.Rules = append(stats.Rules, ApprovalRuleStats{
+					Name:              rule.Name,
+					Type:              rule.RuleType,
+					ApprovalsRequired: rule.ApprovalsRequired,
+					ApprovalsGiven:    len(rule.ApprovedBy),
+					Approved:          rule.Approved,
+				})
+			}
+		}
+
+		result = append(result, stats)
 	}
 
 	return &result, nil
 }
 
-func getChanges(c *gitlab.Client, mergeStats []MergeRequestStats) (*[]ChangeStats, error) {
+func containsUser(users []*gitlab.BasicUser, userID int) bool {
+	for _, user := range users {
+		if user != nil && user.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+//getCurrentUserID returns the ID of the user of the token, which is only retrieved once.
+//When the user can't be retrieved 0 is returned and it is tried again on the next scrape.
+func (c *ExporterClient) getCurrentUserID(glc *gitlab.Client) int {
+	c.mutex.Lock()
+	userID := c.currentUserID
+	c.mutex.Unlock()
+
+	if userID != 0 {
+		return userID
+	}
+
+	user, _, err := glc.Users.CurrentUser(gitlab.WithContext(c.ctx))
+	if err != nil {
+		log.Warn("Unable to retrieve the user of the token, skipping the approvals awaiting the user: ", err)
+		return 0
+	}
+
+	c.mutex.Lock()
+	c.currentUserID = user.ID
+	c.mutex.Unlock()
+
+	return user.ID
+}
+
+//getChanges compares the source branch of each merge request with its target branch.
+//The source branch of a MR from a fork lives in another project, so with includeForks the changes of those are retrieved from the MR itself, otherwise they are left out.
+//Merge requests of which a branch doesn't exist are skipped and counted, instead of failing the scrape.
+//The changes to files of the given extensions are also counted per extension.
+func getChanges(ctx context.Context, c *gitlab.Client, mergeStats []MergeRequestStats, extensions []string, includeForks bool) (*[]ChangeStats, int, error) {
 
 	var result []ChangeStats
+	skipped := 0
 
 	for _, mr := range mergeStats {
 
-		compareResult, _, err := c.Repositories.Compare(mr.ProjectID, &gitlab.CompareOptions{
-			From: gitlab.String("master"),
-			To:   gitlab.String(mr.SourceBranch),
-		})
+		if isFork(mr) && !includeForks {
+			log.Debug("Skipping the changes of MR ", mr.ID, ", its source branch lives in a fork")
+			continue
+		}
+
+		diffs, resp, err := getDiffs(ctx, c, mr)
 		if err != nil {
-			return nil, err
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				log.Debug("Skipping the changes of MR ", mr.ID, ", a compared branch doesn't exist in project ", mr.ProjectID)
+				skipped++
+				continue
+			}
+			return nil, 0, err
 		}
 
 		additions := 0
 		deletions := 0
-		for _, diff := range compareResult.Diffs {
-			additions += strings.Count(diff.Diff, "\n+")
-			deletions += strings.Count(diff.Diff, "\n-")
+		diffBytes := 0
+		byExtension := map[string]*ExtensionChangeStats{}
+		for _, diff := range diffs {
+			added, deleted := countDiffLines(diff.diff)
+			additions += added
+			deletions += deleted
+			diffBytes += len(diff.diff)
+
+			extension := fileExtension(diff.path)
+			if !containsString(extensions, extension) {
+				continue
+			}
+			if _, ok := byExtension[extension]; !ok {
+				byExtension[extension] = &ExtensionChangeStats{Extension: extension}
+			}
+			byExtension[extension].Additions += added
+			byExtension[extension].Deletions += deleted
 		}
 
-		result = append(result, ChangeStats{
+		stats := ChangeStats{
 			ID:        mr.ID,
 			ProjectID: mr.ProjectID,
 			Additions: additions,
 			Deletions: deletions,
+			DiffBytes: diffBytes,
+		}
+		for _, extension := range extensions {
+			if changes, ok := byExtension[extension]; ok {
+				stats.Extensions = append(stats.Extensions, *changes)
+			}
+		}
+
+		result = append(result, stats)
+	}
+
+	return &result, skipped, nil
+}
+
+//countDiffLines counts the added and deleted lines of a unified diff.
+//The file headers before the first hunk are left out, so a deleted line starting with -- within a hunk still counts as deleted.
+func countDiffLines(diff string) (int, int) {
+
+	added := 0
+	deleted := 0
+	inHunk := false
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case strings.HasPrefix(line, "diff --git "):
+			inHunk = false
+		case !inHunk:
+			continue
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			deleted++
+		}
+	}
+
+	return added, deleted
+}
+
+//fileDiff is the diff of a single file.
+type fileDiff struct {
+	path string
+	diff string
+}
+
+//getDiffs retrieves the diffs of the files changed by the MR.
+func getDiffs(ctx context.Context, c *gitlab.Client, mr MergeRequestStats) ([]fileDiff, *gitlab.Response, error) {
+
+	var result []fileDiff
+
+	if isFork(mr) {
+		changes, resp, err := c.MergeRequests.GetMergeRequestChanges(mr.ProjectID, mr.InternalID, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, resp, err
+		}
+		for _, change := range changes.Changes {
+			result = append(result, fileDiff{path: change.NewPath, diff: change.Diff})
+		}
+		return result, resp, nil
+	}
+
+	compareResult, resp, err := c.Repositories.Compare(mr.ProjectID, &gitlab.CompareOptions{
+		From: gitlab.String(mr.TargetBranch),
+		To:   gitlab.String(mr.SourceBranch),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, resp, err
+	}
+	for _, diff := range compareResult.Diffs {
+		result = append(result, fileDiff{path: diff.NewPath, diff: diff.Diff})
+	}
+	return result, resp, nil
+}
+
+//isFork returns whether the source branch of the MR lives in another project than the MR.
+func isFork(mr MergeRequestStats) bool {
+	return mr.SourceProjectID != "" && mr.SourceProjectID != mr.ProjectID
+}
+
+//withoutTargetBranches returns the MRs of which the target branch doesn't match any of the glob patterns, and the amount of MRs that were left out.
+func withoutTargetBranches(mrs []MergeRequestStats, patterns []string) ([]MergeRequestStats, int) {
+	var result []MergeRequestStats
+	for _, mr := range mrs {
+		if !matchesAny(mr.TargetBranch, patterns) {
+			result = append(result, mr)
+		}
+	}
+	return result, len(mrs) - len(result)
+}
+
+//withPaths returns the MRs that change a file matching any of the glob patterns, and the amount of MRs that were left out.
+//A pattern also matches the files within the directories it matches, so services/* matches every file below services.
+func withPaths(ctx context.Context, c *gitlab.Client, mrs []MergeRequestStats, patterns []string) ([]MergeRequestStats, int, error) {
+
+	touches := make([]bool, len(mrs))
+
+	err := forEach(len(mrs), func(i int) error {
+		changes, _, err := c.MergeRequests.GetMergeRequestChanges(mrs[i].ProjectID, mrs[i].InternalID, gitlab.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+
+		for _, change := range changes.Changes {
+			if matchesPath(change.NewPath, patterns) || matchesPath(change.OldPath, patterns) {
+				touches[i] = true
+				return nil
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var result []MergeRequestStats
+	for i, mr := range mrs {
+		if touches[i] {
+			result = append(result, mr)
+		}
+	}
+	return result, len(mrs) - len(result), nil
+}
+
+//matchesPath returns whether the file path or one of its parent directories matches any of the glob patterns.
+func matchesPath(filePath string, patterns []string) bool {
+	for current := filePath; current != "." && current != "/" && current != ""; current = path.Dir(current) {
+		if matchesAny(current, patterns) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(value string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+//fileExtension returns the lower case extension of the file path without the leading dot.
+func fileExtension(filePath string) string {
+	return strings.ToLower(strings.TrimPrefix(path.Ext(filePath), "."))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+//trackMergeRequestUpdates compares the last update of each MR with the previous scrape and counts the changes.
+//MRs that are no longer within the retrieved set are forgotten, so their count starts over when they show up again.
+func (c *ExporterClient) trackMergeRequestUpdates(open []MergeRequestStats, merged []MergeMergedStats, closed []MergeClosedStats) *[]UpdateStats {
+
+	mrs := append([]MergeRequestStats{}, open...)
+	for _, mr := range merged {
+		mrs = append(mrs, mr.MergeRequest)
+	}
+	for _, mr := range closed {
+		mrs = append(mrs, mr.MergeRequest)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	lastUpdated := map[string]time.Time{}
+	updateCounts := map[string]int{}
+	var result []UpdateStats
+
+	for _, mr := range mrs {
+		if mr.LastUpdated == nil {
+			continue
+		}
+
+		count := c.updateCounts[mr.ID]
+		if previous, ok := c.lastUpdated[mr.ID]; ok && !previous.Equal(*mr.LastUpdated) {
+			count++
+		}
+
+		lastUpdated[mr.ID] = *mr.LastUpdated
+		updateCounts[mr.ID] = count
+
+		result = append(result, UpdateStats{
+			ID:        mr.ID,
+			ProjectID: mr.ProjectID,
+			Updates:   count,
 		})
 	}
 
-	return &result, nil
+	c.lastUpdated = lastUpdated
+	c.updateCounts = updateCounts
+
+	return &result
+}
+
+//pipelineStatus returns the status of the head pipeline of the MR, or an empty string if the MR has no pipeline.
+func pipelineStatus(mr *gitlab.MergeRequest) string {
+	switch {
+	case mr.HeadPipeline != nil:
+		return mr.HeadPipeline.Status
+	case mr.Pipeline != nil:
+		return mr.Pipeline.Status
+	}
+	return ""
+}
+
+//username returns the username of the user, or an empty string if there is no user.
+func username(user *gitlab.BasicUser) string {
+	if user == nil {
+		return ""
+	}
+	return user.Username
 }
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCountDiffLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		diff    string
+		added   int
+		deleted int
+	}{
+		{
+			name:    "hunk without file headers",
+			diff:    "@@ -1,2 +1,2 @@\n-old\n+new\n context\n",
+			added:   1,
+			deleted: 1,
+		},
+		{
+			name: "multiple files with headers",
+			diff: "diff --git a/main.go b/main.go\n" +
+				"--- a/main.go\n" +
+				"+++ b/main.go\n" +
+				"@@ -1,3 +1,4 @@\n" +
+				" package main\n" +
+				"-import \"fmt\"\n" +
+				"+import (\n" +
+				"+\t\"fmt\"\n" +
+				"+)\n" +
+				"diff --git a/schema.sql b/schema.sql\n" +
+				"--- a/schema.sql\n" +
+				"+++ b/schema.sql\n" +
+				"@@ -1,2 +1,2 @@\n" +
+				"--- a removed SQL comment\n" +
+				"+++ an added line starting with ++\n" +
+				"diff --git a/README.md b/README.md\n" +
+				"--- a/README.md\n" +
+				"+++ b/README.md\n" +
+				"@@ -1 +1 @@\n" +
+				"-title\n" +
+				"\\ No newline at end of file\n" +
+				"+Title\n" +
+				"\\ No newline at end of file",
+			added:   5,
+			deleted: 3,
+		},
+		{
+			name:    "first line of the hunk is a change",
+			diff:    "@@ -0,0 +1,2 @@\n+first\n+second",
+			added:   2,
+			deleted: 0,
+		},
+		{
+			name:    "empty diff",
+			diff:    "",
+			added:   0,
+			deleted: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			added, deleted := countDiffLines(test.diff)
+			if added != test.added || deleted != test.deleted {
+				t.Errorf("expected %d added and %d deleted lines, got %d and %d", test.added, test.deleted, added, deleted)
+			}
+		})
+	}
+}
+
+func TestGetApprovalsSkipsInaccessibleMergeRequests(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/1/merge_requests/7/approvals", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "404 Not found"}`, http.StatusNotFound)
+	})
+	mux.HandleFunc("/api/v4/projects/1/merge_requests/8/approvals", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"approvals_left": 1}`)
+	})
+	c := newTestClient(t, mux)
+
+	mrs := []MergeRequestStats{{ID: "70", InternalID: 7, ProjectID: "1"}, {ID: "80", InternalID: 8, ProjectID: "1"}}
+
+	_, err := getApprovals(context.Background(), c, mrs, false, 0, true)
+	if !errors.Is(err, errApprovalsUnavailable) {
+		t.Errorf("expected a 404 on the first request to mean approvals are unavailable, got %v", err)
+	}
+
+	approvals, err := getApprovals(context.Background(), c, mrs, false, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*approvals) != 1 || (*approvals)[0].ID != "80" || (*approvals)[0].Approvals != 1 {
+		t.Errorf("expected only the approvals of MR 80, got %+v", *approvals)
+	}
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"strings"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//hasNextPage returns whether the Link header of the response points to a next page.
+//Responses without a Link header fall back to the X-Next-Page header.
+func hasNextPage(resp *gitlab.Response) bool {
+	links := resp.Header.Values("Link")
+	if len(links) == 0 {
+		return resp.NextPage != 0
+	}
+
+	for _, header := range links {
+		for _, link := range strings.Split(header, ",") {
+			for _, param := range strings.Split(link, ";")[1:] {
+				if strings.TrimSpace(param) == `rel="next"` {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//CIMinutesStats is the struct for the CI minutes consumed by a project.
+type CIMinutesStats struct {
+	ProjectID string
+	Minutes   float64
+}
+
+//PipelineStatusStats is the struct for the status of the latest pipeline on the default branch of a project.
+type PipelineStatusStats struct {
+	ProjectID string
+	Status    string
+
+	//LastSuccessAt is nil when the default branch has no successful pipelines.
+	LastSuccessAt *time.Time
+}
+
+//getPipelineStatuses retrieves the status of the latest pipeline on the default branch of the projects, and when it didn't succeed the latest successful one.
+//Projects without a default branch or without pipelines are skipped.
+func getPipelineStatuses(ctx context.Context, c *gitlab.Client, projects []ProjectStats) (*[]PipelineStatusStats, error) {
+
+	results := make([]*PipelineStatusStats, len(projects))
+
+	err := forEach(len(projects), func(i int) error {
+		project := projects[i]
+		if project.DefaultBranch == "" {
+			return nil
+		}
+
+		pipelines, _, err := c.Pipelines.ListProjectPipelines(project.ID, &gitlab.ListProjectPipelinesOptions{
+			ListOptions: gitlab.ListOptions{Page: 1, PerPage: 1},
+			Ref:         gitlab.String(project.DefaultBranch),
+			OrderBy:     gitlab.String("id"),
+			Sort:        gitlab.String("desc"),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+
+		if len(pipelines) == 0 {
+			return nil
+		}
+
+		results[i] = &PipelineStatusStats{
+			ProjectID: project.ID,
+			Status:    pipelines[0].Status,
+		}
+
+		if pipelines[0].Status == string(gitlab.Success) {
+			results[i].LastSuccessAt = pipelines[0].UpdatedAt
+			return nil
+		}
+
+		successful, _, err := c.Pipelines.ListProjectPipelines(project.ID, &gitlab.ListProjectPipelinesOptions{
+			ListOptions: gitlab.ListOptions{Page: 1, PerPage: 1},
+			Ref:         gitlab.String(project.DefaultBranch),
+			Status:      gitlab.BuildState(gitlab.Success),
+			OrderBy:     gitlab.String("id"),
+			Sort:        gitlab.String("desc"),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+
+		if len(successful) > 0 {
+			results[i].LastSuccessAt = successful[0].UpdatedAt
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []PipelineStatusStats
+	for _, status := range results {
+		if status != nil {
+			result = append(result, *status)
+		}
+	}
+
+	return &result, nil
+}
+
+//getCIMinutes sums the duration of all jobs of the last 7 days per project.
+func getCIMinutes(ctx context.Context, c *gitlab.Client, projects []ProjectStats) (*[]CIMinutesStats, error) {
+
+	since := time.Now().Add(-Window)
+	result := make([]CIMinutesStats, len(projects))
+
+	err := forEach(len(projects), func(i int) error {
+		project := projects[i]
+		seconds := 0.0
+		page := 1
+
+	pages:
+		for {
+			jobs, resp, err := c.Jobs.ListProjectJobs(project.ID, &gitlab.ListJobsOptions{
+				ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
+			}, gitlab.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+
+			// Jobs are returned newest first, so stop at the first job outside of the window.
+			for _, job := range jobs {
+				if job.CreatedAt != nil && job.CreatedAt.Before(since) {
+					break pages
+				}
+				seconds += job.Duration
+			}
+
+			if !hasNextPage(resp) {
+				break
+			}
+			page++
+		}
+
+		result[i] = CIMinutesStats{
+			ProjectID: project.ID,
+			Minutes:   seconds / 60,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+//PipelineRetryStats is the struct for the amount of pipelines that ran again for a commit of an open MR.
+type PipelineRetryStats struct {
+	ID        string
+	ProjectID string
+	Retries   int
+}
+
+//getPipelineRetries counts the pipelines on the source branch of the open MRs that ran again for a commit of which an earlier pipeline failed.
+//Pipelines for a commit without a failed pipeline before them, like new pushes of the same commit or manual runs, aren't retries.
+//MRs without pipelines on their source branch are skipped.
+func getPipelineRetries(ctx context.Context, c *gitlab.Client, mergeStats []MergeRequestStats) (*[]PipelineRetryStats, error) {
+
+	results := make([]*PipelineRetryStats, len(mergeStats))
+
+	err := forEach(len(mergeStats), func(i int) error {
+		mr := mergeStats[i]
+
+		total := 0
+		retries := 0
+		failed := map[string]bool{}
+		page := 1
+
+		for {
+			pipelines, resp, err := c.Pipelines.ListProjectPipelines(mr.SourceProjectID, &gitlab.ListProjectPipelinesOptions{
+				ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
+				Ref:         gitlab.String(mr.SourceBranch),
+				OrderBy:     gitlab.String("id"),
+				Sort:        gitlab.String("asc"),
+			}, gitlab.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+
+			for _, pipeline := range pipelines {
+				total++
+				if failed[pipeline.SHA] {
+					retries++
+				}
+				if pipeline.Status == string(gitlab.Failed) {
+					failed[pipeline.SHA] = true
+				}
+			}
+
+			if !hasNextPage(resp) {
+				break
+			}
+			page++
+		}
+
+		if total == 0 {
+			return nil
+		}
+
+		results[i] = &PipelineRetryStats{
+			ID:        mr.ID,
+			ProjectID: mr.ProjectID,
+			Retries:   retries,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []PipelineRetryStats
+	for _, retries := range results {
+		if retries != nil {
+			result = append(result, *retries)
+		}
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//newTestClient returns a Gitlab client doing its requests to the handler.
+func newTestClient(t *testing.T, handler http.Handler) *gitlab.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestGetPipelineRetries(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/1/pipelines", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sort") != "asc" {
+			t.Errorf("expected the pipelines in chronological order, got %q", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `[
+			{"id": 1, "sha": "a", "status": "success"},
+			{"id": 2, "sha": "a", "status": "success"},
+			{"id": 3, "sha": "b", "status": "failed"},
+			{"id": 4, "sha": "b", "status": "failed"},
+			{"id": 5, "sha": "b", "status": "success"},
+			{"id": 6, "sha": "c", "status": "success"}
+		]`)
+	})
+	c := newTestClient(t, mux)
+
+	retries, err := getPipelineRetries(context.Background(), c, []MergeRequestStats{{ID: "70", ProjectID: "1", SourceProjectID: "1", SourceBranch: "feature"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*retries) != 1 || (*retries)[0].Retries != 2 {
+		t.Fatalf("expected 2 retries after the failed pipelines, got %+v", *retries)
+	}
+}
@@ -1,7 +1,9 @@
 package client
 
 import (
+	"context"
 	"strconv"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	gitlab "github.com/xanzy/go-gitlab"
@@ -11,40 +13,118 @@ import (
 type ProjectStats struct {
 	ID                string
 	PathWithNamespace string
+	DefaultBranch     string
+
+	RequirePipelineSuccess bool
+
+	//RepositorySize is nil when the token is not allowed to see the project statistics.
+	RepositorySize *int64
 }
 
 //getProjectStats retrieves all projects from Gitlab.
-func getProjects(c *gitlab.Client) (*[]ProjectStats, error) {
+//The response is returned along with an error, so the caller can tell why listing failed.
+//A minActivity above 0 only lists the projects with activity within that duration, which Gitlab filters on its side.
+//With membership only the projects the user of the token is a member of are listed, instead of all projects visible to it.
+func getProjects(ctx context.Context, c *gitlab.Client, minActivity time.Duration, membership bool) (*[]ProjectStats, *gitlab.Response, error) {
 	var result []ProjectStats
 	var projectsTotal []*gitlab.Project
 
+	opt := &gitlab.ListProjectsOptions{
+		Archived:   gitlab.Bool(false),
+		Statistics: gitlab.Bool(true),
+	}
+	if minActivity > 0 {
+		opt.LastActivityAfter = gitlab.Time(time.Now().Add(-minActivity))
+	}
+	if membership {
+		opt.Membership = gitlab.Bool(true)
+	}
+
 	page := 1
 
 	for {
-		projects, _, err := c.Projects.ListProjects(&gitlab.ListProjectsOptions{
-			ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
-			Archived:    gitlab.Bool(false),
-			Simple:      gitlab.Bool(true),
-		})
+		opt.ListOptions = gitlab.ListOptions{Page: page, PerPage: 100}
+
+		// The simple representation lacks the merge settings, so the full one is listed.
+		projects, resp, err := c.Projects.ListProjects(opt, gitlab.WithContext(ctx))
 		if err != nil {
-			return nil, err
+			return nil, resp, err
 		}
-
-		if len(projects) == 0 {
+		projectsTotal = append(projectsTotal, projects...)
+		if !hasNextPage(resp) {
 			break
 		}
-		projectsTotal = append(projectsTotal, projects...)
 		page++
 	}
 
-	log.Info("found a total of: ", len(projectsTotal), " projects")
+	log.Debug("found a total of: ", len(projectsTotal), " projects")
 
 	for _, project := range projectsTotal {
-		result = append(result, ProjectStats{
-			ID:                strconv.Itoa(project.ID),
-			PathWithNamespace: project.PathWithNamespace,
-		})
+		result = append(result, projectStats(project))
+	}
+
+	return &result, nil, nil
+}
+
+func projectStats(project *gitlab.Project) ProjectStats {
+	stats := ProjectStats{
+		ID:                strconv.Itoa(project.ID),
+		PathWithNamespace: project.PathWithNamespace,
+		DefaultBranch:     project.DefaultBranch,
+
+		RequirePipelineSuccess: project.OnlyAllowMergeIfPipelineSucceeds,
+	}
+	if project.Statistics != nil {
+		size := project.Statistics.RepositorySize
+		stats.RepositorySize = &size
+	}
+	return stats
+}
+
+//addPinnedProjects retrieves the pinned projects that weren't listed, e.g. because they are archived, so they are always exported.
+//Pinned projects that can't be retrieved, e.g. because they were renamed or the token has no access, are skipped.
+func addPinnedProjects(ctx context.Context, c *gitlab.Client, projects []ProjectStats, pinned []string) []ProjectStats {
+
+	listed := map[string]bool{}
+	for _, project := range projects {
+		listed[project.ID] = true
+		listed[project.PathWithNamespace] = true
 	}
 
-	return &result, nil
+	for _, pin := range pinned {
+		if listed[pin] {
+			continue
+		}
+
+		project, _, err := c.Projects.GetProject(pin, &gitlab.GetProjectOptions{Statistics: gitlab.Bool(true)}, gitlab.WithContext(ctx))
+		if err != nil {
+			log.Warn("Unable to retrieve pinned project ", pin, ", skipping it: ", err)
+			continue
+		}
+
+		stats := projectStats(project)
+		listed[stats.ID] = true
+		listed[stats.PathWithNamespace] = true
+		projects = append(projects, stats)
+	}
+
+	return projects
+}
+
+//logMissingStatistics warns once when the statistics of any project are not visible to the token.
+func (c *ExporterClient) logMissingStatistics(projects []ProjectStats) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.statisticsMissingLogged {
+		return
+	}
+
+	for _, project := range projects {
+		if project.RepositorySize == nil {
+			log.Warn("Project statistics are not available for ", project.PathWithNamespace, ", omitting the repository size of projects without statistics")
+			c.statisticsMissingLogged = true
+			return
+		}
+	}
 }
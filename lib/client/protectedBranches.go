@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//ProtectedBranchStats is the struct for the approvals required by the protection of a target branch.
+type ProtectedBranchStats struct {
+	ProjectID         string
+	Branch            string
+	ApprovalsRequired int
+}
+
+//getProtectedBranchApprovals retrieves the protection of the target branches of the open MRs.
+//The approvals required are the sum of the project approval rules scoped to the protected branch, rules that apply to all branches are left out.
+//Target branches that aren't protected are skipped, as are projects and branches of which the lookup fails, so the other details are still retrieved.
+func getProtectedBranchApprovals(ctx context.Context, c *gitlab.Client, mrs []MergeRequestStats) *[]ProtectedBranchStats {
+
+	branches := map[string][]string{}
+	for _, mr := range mrs {
+		if mr.TargetBranch != "" && !containsString(branches[mr.ProjectID], mr.TargetBranch) {
+			branches[mr.ProjectID] = append(branches[mr.ProjectID], mr.TargetBranch)
+		}
+	}
+
+	var result []ProtectedBranchStats
+
+	for projectID, names := range branches {
+		rules, resp, err := c.Projects.GetProjectApprovalRules(projectID, gitlab.WithContext(ctx))
+		if err != nil {
+			// Approval rules are only available on Gitlab premium.
+			if resp == nil || (resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusNotFound) {
+				log.Warn("Unable to retrieve the approval rules of project ", projectID, ", skipping its protected branches: ", err)
+				continue
+			}
+			rules = nil
+		}
+
+		for _, name := range names {
+			branch, resp, err := c.ProtectedBranches.GetProtectedBranch(projectID, name, gitlab.WithContext(ctx))
+			if err != nil {
+				if resp == nil || resp.StatusCode != http.StatusNotFound {
+					log.Warn("Unable to retrieve the protection of branch ", name, " of project ", projectID, ": ", err)
+				}
+				continue
+			}
+
+			stats := ProtectedBranchStats{ProjectID: projectID, Branch: name}
+			for _, rule := range rules {
+				for _, protected := range rule.ProtectedBranches {
+					if protected != nil && protected.ID == branch.ID {
+						stats.ApprovalsRequired += rule.ApprovalsRequired
+					}
+				}
+			}
+
+			result = append(result, stats)
+		}
+	}
+
+	return &result
+}
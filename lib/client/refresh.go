@@ -0,0 +1,194 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+//startProjectRefreshes starts a background loop per distinct interval of the project interval overrides.
+func (c *ExporterClient) startProjectRefreshes() {
+
+	grouped := map[time.Duration][]string{}
+	for path, interval := range c.projectIntervals {
+		grouped[interval] = append(grouped[interval], path)
+	}
+
+	for interval, paths := range grouped {
+		paths := paths
+		c.startTicker(interval, fmt.Sprintf("refresh %s", interval), func() error {
+			return c.refreshProjects(paths)
+		})
+	}
+}
+
+//refreshProjects retrieves the MRs of the given projects again and replaces their entries in the cached stats.
+//Only the MR listing, details, approvals, changes and pickups are refreshed, everything else follows the normal interval.
+//Projects that aren't part of the cached projects yet are skipped until the next full scrape.
+func (c *ExporterClient) refreshProjects(paths []string) error {
+
+	start := time.Now()
+
+	glc, err := c.gitlabClient()
+	if err != nil {
+		return withStage("client", err)
+	}
+
+	c.mutex.Lock()
+	projects := *c.listedStats.Projects
+	c.mutex.Unlock()
+
+	var mrs []MergeRequestStats
+	var projectIDs []string
+	for _, project := range projects {
+		if !containsString(paths, project.PathWithNamespace) {
+			continue
+		}
+		projectMRs, err := getProjectMergeRequests(c.ctx, glc, project.ID, c.listMergeRequestsOptions())
+		if err != nil {
+			return withStage("merge_requests", err)
+		}
+		mrs = append(mrs, *projectMRs...)
+		projectIDs = append(projectIDs, project.ID)
+	}
+
+	// Every listed MR replaces its cached entries, also when a filter below leaves it out.
+	refreshed := map[string]bool{}
+	for _, mr := range mrs {
+		refreshed[mr.ID] = true
+	}
+
+	if len(c.excludeTargetBranches) > 0 {
+		mrs, _ = withoutTargetBranches(mrs, c.excludeTargetBranches)
+	}
+	if len(c.pathFilter) > 0 {
+		mrs, _, err = withPaths(c.ctx, glc, mrs, c.pathFilter)
+		if err != nil {
+			return withStage("changes", err)
+		}
+	}
+
+	mrOpen, mrMerged, mrClosed, mergeErrors, err := getMergeRequestsDetails(c.ctx, glc, mrs, c.changesRetryDelay)
+	if err != nil {
+		return withStage("merge_requests", err)
+	}
+
+	c.mutex.Lock()
+	for _, mr := range mrs {
+		c.detailFetches[mr.State]++
+	}
+	for state, count := range mergeErrors {
+		c.mergeErrorSkips[state] += count
+	}
+	c.mutex.Unlock()
+
+	approvals, err := c.getAvailableApprovals(glc, *mrOpen, true)
+	if err != nil {
+		return withStage("approvals", err)
+	}
+
+	var merged []MergeRequestStats
+	for _, mr := range *mrMerged {
+		merged = append(merged, mr.MergeRequest)
+	}
+
+	mergedApprovals, err := c.getAvailableApprovals(glc, merged, false)
+	if err != nil {
+		return withStage("approvals", err)
+	}
+
+	changes, skipped, err := getChanges(c.ctx, glc, *mrOpen, c.changeExtensions, c.includeForks)
+	if err != nil {
+		return withStage("changes", err)
+	}
+
+	pickups, err := getPickupTimes(c.ctx, glc, append(append([]MergeRequestStats{}, *mrOpen...), merged...))
+	if err != nil {
+		return withStage("pickups", err)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.compareSkips += skipped
+
+	listed := *c.listedStats
+	listed.MergeRequests = &[]MergeRequestStats{}
+	listed.MergeRequestsOpen = &[]MergeRequestStats{}
+	listed.MergeRequestsMerged = &[]MergeMergedStats{}
+	listed.MergeRequestsClosed = &[]MergeClosedStats{}
+
+	details := *c.detailStats
+	details.Approvals = &[]ApprovalStats{}
+	details.MergedApprovals = &[]ApprovalStats{}
+	details.Changes = &[]ChangeStats{}
+	details.Pickups = &[]PickupStats{}
+
+	for _, mr := range *c.listedStats.MergeRequests {
+		if !refreshed[mr.ID] {
+			*listed.MergeRequests = append(*listed.MergeRequests, mr)
+		}
+	}
+	for _, mr := range *c.listedStats.MergeRequestsOpen {
+		if !refreshed[mr.ID] {
+			*listed.MergeRequestsOpen = append(*listed.MergeRequestsOpen, mr)
+		}
+	}
+	for _, mr := range *c.listedStats.MergeRequestsMerged {
+		if !refreshed[mr.MergeRequest.ID] {
+			*listed.MergeRequestsMerged = append(*listed.MergeRequestsMerged, mr)
+		}
+	}
+	for _, mr := range *c.listedStats.MergeRequestsClosed {
+		if !refreshed[mr.MergeRequest.ID] {
+			*listed.MergeRequestsClosed = append(*listed.MergeRequestsClosed, mr)
+		}
+	}
+	for _, approval := range *c.detailStats.Approvals {
+		if !refreshed[approval.ID] {
+			*details.Approvals = append(*details.Approvals, approval)
+		}
+	}
+	for _, approval := range *c.detailStats.MergedApprovals {
+		if !refreshed[approval.ID] {
+			*details.MergedApprovals = append(*details.MergedApprovals, approval)
+		}
+	}
+	for _, change := range *c.detailStats.Changes {
+		if !refreshed[change.ID] {
+			*details.Changes = append(*details.Changes, change)
+		}
+	}
+	for _, pickup := range *c.detailStats.Pickups {
+		if !refreshed[pickup.ID] {
+			*details.Pickups = append(*details.Pickups, pickup)
+		}
+	}
+
+	*listed.MergeRequests = append(*listed.MergeRequests, mrs...)
+	*listed.MergeRequestsOpen = append(*listed.MergeRequestsOpen, *mrOpen...)
+	*listed.MergeRequestsMerged = append(*listed.MergeRequestsMerged, *mrMerged...)
+	*listed.MergeRequestsClosed = append(*listed.MergeRequestsClosed, *mrClosed...)
+
+	// The store holds every retained MR of all projects, so it replaces the merged and closed MRs like a full scrape does.
+	if c.store != nil {
+		listed.MergeRequestsMerged, listed.MergeRequestsClosed = c.store.update(*mrMerged, *mrClosed)
+	}
+	*details.Approvals = append(*details.Approvals, *approvals...)
+	*details.MergedApprovals = append(*details.MergedApprovals, *mergedApprovals...)
+	*details.Changes = append(*details.Changes, *changes...)
+	*details.Pickups = append(*details.Pickups, *pickups...)
+
+	c.listedStats = &listed
+	c.detailStats = &details
+	c.composeStats()
+
+	for _, projectID := range projectIDs {
+		c.projectsLastSeen[projectID] = start
+	}
+
+	log.Debug("Refreshed ", len(mrs), " MRs of ", len(paths), " projects")
+
+	return nil
+}
@@ -0,0 +1,334 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//PickupStats is the struct for the time it took before a reviewer was assigned to a MR.
+type PickupStats struct {
+	ID        string
+	ProjectID string
+	Seconds   float64
+}
+
+//getPickupTimes retrieves the time between creating the MR and requesting the first review.
+//Reviewers that are requested when creating the MR don't leave a system note, so those MRs get a pickup time of 0.
+func getPickupTimes(ctx context.Context, c *gitlab.Client, mergeStats []MergeRequestStats) (*[]PickupStats, error) {
+
+	results := make([]*PickupStats, len(mergeStats))
+
+	err := forEach(len(mergeStats), func(i int) error {
+		mr := mergeStats[i]
+
+		note, err := getFirstReviewRequest(ctx, c, mr)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case note != nil:
+			results[i] = &PickupStats{
+				ID:        mr.ID,
+				ProjectID: mr.ProjectID,
+				Seconds:   note.CreatedAt.Sub(*mr.CreatedAt).Seconds(),
+			}
+		case mr.Reviewers > 0:
+			results[i] = &PickupStats{
+				ID:        mr.ID,
+				ProjectID: mr.ProjectID,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []PickupStats
+	for _, pickup := range results {
+		if pickup != nil {
+			result = append(result, *pickup)
+		}
+	}
+
+	return &result, nil
+}
+
+//getFirstReviewRequest returns the first system note of the MR that requests a review, if there is one.
+func getFirstReviewRequest(ctx context.Context, c *gitlab.Client, mr MergeRequestStats) (*gitlab.Note, error) {
+
+	page := 1
+
+	for {
+		notes, resp, err := c.Notes.ListMergeRequestNotes(mr.ProjectID, mr.InternalID, &gitlab.ListMergeRequestNotesOptions{
+			ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
+			OrderBy:     gitlab.String("created_at"),
+			Sort:        gitlab.String("asc"),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, note := range notes {
+			if note.System && note.CreatedAt != nil && strings.HasPrefix(note.Body, "requested review from") {
+				return note, nil
+			}
+		}
+
+		if !hasNextPage(resp) {
+			return nil, nil
+		}
+		page++
+	}
+}
+
+//ChangesRequestedStats is the struct for the amount of reviewers that requested changes on a MR.
+type ChangesRequestedStats struct {
+	ID        string
+	ProjectID string
+	Reviewers int
+}
+
+//getChangesRequested counts the reviewers of which the latest review of the MR requested changes.
+//Gitlab leaves a system note when changes are requested or the MR is approved, so an approval after requesting changes isn't counted.
+func getChangesRequested(ctx context.Context, c *gitlab.Client, mergeStats []MergeRequestStats) (*[]ChangesRequestedStats, error) {
+
+	results := make([]ChangesRequestedStats, len(mergeStats))
+
+	err := forEach(len(mergeStats), func(i int) error {
+		mr := mergeStats[i]
+
+		requested := map[int]bool{}
+		page := 1
+
+		for {
+			notes, resp, err := c.Notes.ListMergeRequestNotes(mr.ProjectID, mr.InternalID, &gitlab.ListMergeRequestNotesOptions{
+				ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
+				OrderBy:     gitlab.String("created_at"),
+				Sort:        gitlab.String("asc"),
+			}, gitlab.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+
+			for _, note := range notes {
+				if !note.System {
+					continue
+				}
+				switch {
+				case strings.HasPrefix(note.Body, "requested changes"):
+					requested[note.Author.ID] = true
+				case strings.HasPrefix(note.Body, "approved this merge request"):
+					requested[note.Author.ID] = false
+				}
+			}
+
+			if !hasNextPage(resp) {
+				break
+			}
+			page++
+		}
+
+		reviewers := 0
+		for _, changesRequested := range requested {
+			if changesRequested {
+				reviewers++
+			}
+		}
+
+		results[i] = ChangesRequestedStats{
+			ID:        mr.ID,
+			ProjectID: mr.ProjectID,
+			Reviewers: reviewers,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+//ApproverApprovalStats is the struct for an approval given on a MR within the window.
+type ApproverApprovalStats struct {
+	ID        string
+	ProjectID string
+	Username  string
+}
+
+//getApproverApprovals retrieves the approvals given within the window on the given MRs from their system notes.
+//Gitlab leaves a note for every approval, so an approver that approves again after unapproving is counted twice.
+func getApproverApprovals(ctx context.Context, c *gitlab.Client, mergeStats []MergeRequestStats) (*[]ApproverApprovalStats, error) {
+
+	windowStart := time.Now().Add(-Window)
+
+	results := make([][]ApproverApprovalStats, len(mergeStats))
+
+	err := forEach(len(mergeStats), func(i int) error {
+		mr := mergeStats[i]
+		page := 1
+
+		for {
+			notes, resp, err := c.Notes.ListMergeRequestNotes(mr.ProjectID, mr.InternalID, &gitlab.ListMergeRequestNotesOptions{
+				ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
+			}, gitlab.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+
+			for _, note := range notes {
+				if !note.System || note.CreatedAt == nil || note.CreatedAt.Before(windowStart) || !strings.HasPrefix(note.Body, "approved this merge request") {
+					continue
+				}
+				results[i] = append(results[i], ApproverApprovalStats{
+					ID:        mr.ID,
+					ProjectID: mr.ProjectID,
+					Username:  note.Author.Username,
+				})
+			}
+
+			if !hasNextPage(resp) {
+				break
+			}
+			page++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ApproverApprovalStats
+	for _, approvals := range results {
+		result = append(result, approvals...)
+	}
+
+	return &result, nil
+}
+
+//ReviewRoundStats is the struct for the estimated amount of review rounds on a MR.
+type ReviewRoundStats struct {
+	ID        string
+	ProjectID string
+	Rounds    int
+}
+
+//getReviewRounds estimates the amount of review rounds of the MRs from their comments in chronological order.
+//A round is one or more comments of others followed by a comment of the author, so comments awaiting a response aren't a round yet.
+//System notes and comments of the author that don't respond to a review are left out.
+func getReviewRounds(ctx context.Context, c *gitlab.Client, mergeStats []MergeRequestStats) (*[]ReviewRoundStats, error) {
+
+	results := make([]ReviewRoundStats, len(mergeStats))
+
+	err := forEach(len(mergeStats), func(i int) error {
+		mr := mergeStats[i]
+
+		rounds := 0
+		reviewed := false
+		page := 1
+
+		for {
+			notes, resp, err := c.Notes.ListMergeRequestNotes(mr.ProjectID, mr.InternalID, &gitlab.ListMergeRequestNotesOptions{
+				ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
+				OrderBy:     gitlab.String("created_at"),
+				Sort:        gitlab.String("asc"),
+			}, gitlab.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+
+			for _, note := range notes {
+				if note.System {
+					continue
+				}
+				switch {
+				case note.Author.Username != mr.Author:
+					reviewed = true
+				case reviewed:
+					rounds++
+					reviewed = false
+				}
+			}
+
+			if !hasNextPage(resp) {
+				break
+			}
+			page++
+		}
+
+		results[i] = ReviewRoundStats{
+			ID:        mr.ID,
+			ProjectID: mr.ProjectID,
+			Rounds:    rounds,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+//DiscussionStats is the struct for the amount of discussions on a MR, by who started them.
+type DiscussionStats struct {
+	ID        string
+	ProjectID string
+	Author    int
+	Reviewer  int
+}
+
+//getDiscussions counts the discussions of the MRs by whether the author of the MR or someone else wrote the first note.
+//Discussions started by a system note, like approvals and pushes, are left out.
+func getDiscussions(ctx context.Context, c *gitlab.Client, mergeStats []MergeRequestStats) (*[]DiscussionStats, error) {
+
+	results := make([]DiscussionStats, len(mergeStats))
+
+	err := forEach(len(mergeStats), func(i int) error {
+		mr := mergeStats[i]
+
+		stats := DiscussionStats{ID: mr.ID, ProjectID: mr.ProjectID}
+		page := 1
+
+		for {
+			discussions, resp, err := c.Discussions.ListMergeRequestDiscussions(mr.ProjectID, mr.InternalID, &gitlab.ListMergeRequestDiscussionsOptions{Page: page, PerPage: 100}, gitlab.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+
+			for _, discussion := range discussions {
+				if len(discussion.Notes) == 0 || discussion.Notes[0].System {
+					continue
+				}
+				if discussion.Notes[0].Author.Username == mr.Author {
+					stats.Author++
+				} else {
+					stats.Reviewer++
+				}
+			}
+
+			if !hasNextPage(resp) {
+				break
+			}
+			page++
+		}
+
+		results[i] = stats
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGetReviewRounds(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/1/merge_requests/7", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 70, "iid": 7, "project_id": 1, "state": "opened", "author": {"username": "alice"}}`)
+	})
+	mux.HandleFunc("/api/v4/projects/1/merge_requests/7/notes", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"body": "before any review", "author": {"username": "alice"}},
+			{"body": "nit", "author": {"username": "bob"}},
+			{"body": "question", "author": {"username": "carol"}},
+			{"body": "fixed", "author": {"username": "alice"}},
+			{"body": "one more", "author": {"username": "bob"}},
+			{"body": "approved this merge request", "system": true, "author": {"username": "alice"}},
+			{"body": "done", "author": {"username": "alice"}},
+			{"body": "awaiting a response", "author": {"username": "bob"}}
+		]`)
+	})
+	c := newTestClient(t, mux)
+
+	open, _, _, _, err := getMergeRequestsDetails(context.Background(), c, []MergeRequestStats{{ID: "70", InternalID: 7, ProjectID: "1", State: "opened"}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*open) != 1 || (*open)[0].Author != "alice" {
+		t.Fatalf("expected the open MR of alice, got %+v", *open)
+	}
+
+	rounds, err := getReviewRounds(context.Background(), c, *open)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*rounds) != 1 || (*rounds)[0].Rounds != 2 {
+		t.Fatalf("expected 2 review rounds, got %+v", *rounds)
+	}
+}
+
+func TestGetDiscussions(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/1/merge_requests/7/discussions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"id": "a", "notes": [{"body": "context for reviewers", "author": {"username": "alice"}}]},
+			{"id": "b", "notes": [{"body": "nit", "author": {"username": "bob"}}, {"body": "fixed", "author": {"username": "alice"}}]},
+			{"id": "c", "notes": [{"body": "question", "author": {"username": "carol"}}]},
+			{"id": "d", "individual_note": true, "notes": [{"body": "added 1 commit", "system": true, "author": {"username": "alice"}}]}
+		]`)
+	})
+	c := newTestClient(t, mux)
+
+	discussions, err := getDiscussions(context.Background(), c, []MergeRequestStats{{ID: "70", InternalID: 7, ProjectID: "1", Author: "alice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*discussions) != 1 || (*discussions)[0].Author != 1 || (*discussions)[0].Reviewer != 2 {
+		t.Fatalf("expected 1 discussion of the author and 2 of reviewers, got %+v", *discussions)
+	}
+}
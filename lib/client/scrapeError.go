@@ -0,0 +1,67 @@
+package client
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//ScrapeError is the struct for the stage and coarse reason of a failed background scrape.
+type ScrapeError struct {
+	Stage  string
+	Reason string
+}
+
+//stageError is an error of a stage of a scrape, like listing the projects.
+type stageError struct {
+	stage string
+	err   error
+}
+
+func (e *stageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stageError) Unwrap() error {
+	return e.err
+}
+
+//withStage marks the error as failing the stage of the scrape, a nil error stays nil.
+func withStage(stage string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &stageError{stage: stage, err: err}
+}
+
+//classifyError derives the stage and reason of the error of a scrape.
+//Errors without a stage are of the stage other.
+func classifyError(err error) *ScrapeError {
+	result := &ScrapeError{Stage: "other", Reason: "other"}
+
+	var stageErr *stageError
+	if errors.As(err, &stageErr) {
+		result.Stage = stageErr.stage
+	}
+
+	// Gitlab responded with an error status, requests without a response are network errors like the ones retried at startup.
+	var errResp *gitlab.ErrorResponse
+	var netErr net.Error
+	switch {
+	case errors.As(err, &errResp) && errResp.Response != nil:
+		switch status := errResp.Response.StatusCode; {
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			result.Reason = "auth"
+		case status == http.StatusTooManyRequests:
+			result.Reason = "ratelimit"
+		case status >= http.StatusInternalServerError:
+			result.Reason = "server"
+		}
+	case errors.As(err, &netErr) && netErr.Timeout():
+		result.Reason = "timeout"
+	}
+
+	return result
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+func TestClassifyError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	})
+	mux.HandleFunc("/api/v4/projects/1/merge_requests/7/approvals", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "403 Forbidden"}`, http.StatusForbidden)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL), gitlab.WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = getProjects(context.Background(), c, 0, false)
+	if got := classifyError(withStage("projects", err)); *got != (ScrapeError{Stage: "projects", Reason: "timeout"}) {
+		t.Errorf("expected a timeout while listing the projects, got %+v for %v", *got, err)
+	}
+
+	_, _, err = c.MergeRequests.GetMergeRequestApprovals(1, 7)
+	if got := classifyError(withStage("approvals", err)); *got != (ScrapeError{Stage: "approvals", Reason: "auth"}) {
+		t.Errorf("expected an auth failure while retrieving the approvals, got %+v for %v", *got, err)
+	}
+
+	if got := classifyError(err); got.Stage != "other" {
+		t.Errorf("expected the stage other for an error without a stage, got %+v", *got)
+	}
+}
+
+func TestFetchDataSkipsCancellations(t *testing.T) {
+	c := &ExporterClient{}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+
+	timedOut := fmt.Errorf("listing projects: %w", context.DeadlineExceeded)
+	c.fetchData("listing", func() error { return withStage("projects", timedOut) })
+	if c.scrapeFailures != 1 || c.lastScrapeErr == nil {
+		t.Errorf("expected a timed out scrape to count as a failure, got %d failures", c.scrapeFailures)
+	}
+
+	c.fetchData("listing", func() error { return withStage("projects", errors.New("500 Internal Server Error")) })
+	if c.scrapeFailures != 2 {
+		t.Errorf("expected a failed scrape to count as a failure, got %d failures", c.scrapeFailures)
+	}
+
+	c.Stop()
+	c.fetchData("listing", func() error { return withStage("projects", context.Canceled) })
+	if c.scrapeFailures != 2 {
+		t.Errorf("expected a scrape cancelled by stopping the client not to count as a failure, got %d failures", c.scrapeFailures)
+	}
+}
+
+func TestFetchDataClassifiesTimeouts(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	glc, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL), gitlab.WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &ExporterClient{}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	defer c.Stop()
+
+	c.fetchData("listing", func() error {
+		_, _, err := getProjects(c.ctx, glc, 0, false)
+		return withStage("projects", err)
+	})
+	if c.scrapeFailures != 1 || c.lastScrapeErr == nil || *c.lastScrapeErr != (ScrapeError{Stage: "projects", Reason: "timeout"}) {
+		t.Errorf("expected a timeout while listing the projects to count as a failure, got %d failures and %+v", c.scrapeFailures, c.lastScrapeErr)
+	}
+}
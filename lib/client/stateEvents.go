@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//stateEvent is the part of a resource state event of a MR we need, go-gitlab doesn't support the endpoint yet.
+type stateEvent struct {
+	State     string     `json:"state"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+//ReopenStats is the struct for whether a MR was reopened after being closed.
+type ReopenStats struct {
+	ID        string
+	ProjectID string
+	Reopened  bool
+}
+
+//getReopens checks for every MR whether it was reopened within the last 7 days, based on the state events of the MR.
+func getReopens(ctx context.Context, c *gitlab.Client, mergeStats []MergeRequestStats) (*[]ReopenStats, error) {
+
+	since := time.Now().Add(-Window)
+	results := make([]ReopenStats, len(mergeStats))
+
+	err := forEach(len(mergeStats), func(i int) error {
+		mr := mergeStats[i]
+		reopened := false
+		page := 1
+
+		for {
+			events, resp, err := listStateEvents(ctx, c, mr, page)
+			if err != nil {
+				return err
+			}
+
+			for _, event := range events {
+				if event.State == "reopened" && event.CreatedAt != nil && event.CreatedAt.After(since) {
+					reopened = true
+				}
+			}
+
+			if reopened || !hasNextPage(resp) {
+				break
+			}
+			page++
+		}
+
+		results[i] = ReopenStats{
+			ID:        mr.ID,
+			ProjectID: mr.ProjectID,
+			Reopened:  reopened,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+//listStateEvents retrieves a page of the state events of the MR.
+func listStateEvents(ctx context.Context, c *gitlab.Client, mr MergeRequestStats, page int) ([]stateEvent, *gitlab.Response, error) {
+
+	path := fmt.Sprintf("projects/%s/merge_requests/%d/resource_state_events", url.PathEscape(mr.ProjectID), mr.InternalID)
+
+	req, err := c.NewRequest(http.MethodGet, path, &gitlab.ListOptions{Page: page, PerPage: 100}, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var events []stateEvent
+
+	resp, err := c.Do(req, &events)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return events, resp, nil
+}
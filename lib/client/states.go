@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//StateDurationStats is the struct for the time a merged MR spent in each state, keyed by state.
+type StateDurationStats struct {
+	ID        string
+	ProjectID string
+	Durations map[string]float64
+}
+
+//readyNotes are the starts of the system notes Gitlab leaves when a draft MR is marked as ready, for current and older Gitlab versions.
+var readyNotes = []string{"marked this merge request as **ready**", "unmarked as a **Work In Progress**", "unmarked as a **draft**"}
+
+//getStateDurations derives the time merged MRs spent as draft, in review and approved from their system notes.
+//A MR is in review from being created or marked as ready until the first approval after that, and approved until it is merged.
+//MRs that were never a draft or never approved don't get a duration for those states.
+func getStateDurations(ctx context.Context, c *gitlab.Client, mergeStats []MergeMergedStats) (*[]StateDurationStats, error) {
+
+	results := make([]*StateDurationStats, len(mergeStats))
+
+	err := forEach(len(mergeStats), func(i int) error {
+		mr := mergeStats[i]
+		if mr.MergeRequest.CreatedAt == nil || mr.MergedAt == nil {
+			return nil
+		}
+
+		var ready, approved *time.Time
+		page := 1
+
+		for {
+			notes, resp, err := c.Notes.ListMergeRequestNotes(mr.MergeRequest.ProjectID, mr.MergeRequest.InternalID, &gitlab.ListMergeRequestNotesOptions{
+				ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
+				OrderBy:     gitlab.String("created_at"),
+				Sort:        gitlab.String("asc"),
+			}, gitlab.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+
+			for _, note := range notes {
+				if !note.System || note.CreatedAt == nil || note.CreatedAt.After(*mr.MergedAt) {
+					continue
+				}
+				switch {
+				case hasAnyPrefix(note.Body, readyNotes):
+					// Marking as ready again restarts the review.
+					ready = note.CreatedAt
+					approved = nil
+				case strings.HasPrefix(note.Body, "approved this merge request") && approved == nil:
+					approved = note.CreatedAt
+				}
+			}
+
+			if !hasNextPage(resp) {
+				break
+			}
+			page++
+		}
+
+		durations := map[string]float64{}
+
+		reviewStart := *mr.MergeRequest.CreatedAt
+		if ready != nil {
+			durations["draft"] = ready.Sub(*mr.MergeRequest.CreatedAt).Seconds()
+			reviewStart = *ready
+		}
+
+		if approved != nil {
+			durations["review"] = approved.Sub(reviewStart).Seconds()
+			durations["approved"] = mr.MergedAt.Sub(*approved).Seconds()
+		} else {
+			durations["review"] = mr.MergedAt.Sub(reviewStart).Seconds()
+		}
+
+		results[i] = &StateDurationStats{
+			ID:        mr.MergeRequest.ID,
+			ProjectID: mr.MergeRequest.ProjectID,
+			Durations: durations,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []StateDurationStats
+	for _, durations := range results {
+		if durations != nil {
+			result = append(result, *durations)
+		}
+	}
+
+	return &result, nil
+}
+
+func hasAnyPrefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"sort"
+	"time"
+)
+
+//mergeRequestStore retains merged and closed MRs after they fall outside of the window, until the retention expires.
+type mergeRequestStore struct {
+	retention time.Duration
+	merged    map[string]MergeMergedStats
+	closed    map[string]MergeClosedStats
+}
+
+func newMergeRequestStore(retention time.Duration) *mergeRequestStore {
+	return &mergeRequestStore{
+		retention: retention,
+		merged:    map[string]MergeMergedStats{},
+		closed:    map[string]MergeClosedStats{},
+	}
+}
+
+//update adds the retrieved MRs to the store, removes the expired ones and returns all retained MRs.
+func (s *mergeRequestStore) update(merged []MergeMergedStats, closed []MergeClosedStats) (*[]MergeMergedStats, *[]MergeClosedStats) {
+
+	for _, mr := range merged {
+		s.merged[mr.MergeRequest.ID] = mr
+	}
+	for _, mr := range closed {
+		s.closed[mr.MergeRequest.ID] = mr
+	}
+
+	expiry := time.Now().Add(-s.retention)
+
+	resultMerged := []MergeMergedStats{}
+	for id, mr := range s.merged {
+		if mr.MergedAt == nil || mr.MergedAt.Before(expiry) {
+			delete(s.merged, id)
+			continue
+		}
+		resultMerged = append(resultMerged, mr)
+	}
+
+	resultClosed := []MergeClosedStats{}
+	for id, mr := range s.closed {
+		if mr.ClosedAt == nil || mr.ClosedAt.Before(expiry) {
+			delete(s.closed, id)
+			continue
+		}
+		resultClosed = append(resultClosed, mr)
+	}
+
+	sort.Slice(resultMerged, func(i, j int) bool { return resultMerged[i].MergedAt.Before(*resultMerged[j].MergedAt) })
+	sort.Slice(resultClosed, func(i, j int) bool { return resultClosed[i].ClosedAt.Before(*resultClosed[j].ClosedAt) })
+
+	return &resultMerged, &resultClosed
+}
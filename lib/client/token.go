@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+//personalAccessToken is the part of the personal access token of the API key we need, go-gitlab doesn't support the endpoint yet.
+type personalAccessToken struct {
+	ExpiresAt *gitlab.ISOTime `json:"expires_at"`
+}
+
+//getTokenExpiry retrieves the date the API key expires, Gitlab revokes the token at the start of that day.
+//Tokens without an expiry date and instances or tokens that don't expose it return nil.
+func getTokenExpiry(ctx context.Context, c *gitlab.Client) (*time.Time, error) {
+
+	req, err := c.NewRequest(http.MethodGet, "personal_access_tokens/self", nil, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return nil, err
+	}
+
+	var token personalAccessToken
+
+	resp, err := c.Do(req, &token)
+	if err != nil {
+		// Older instances don't have the endpoint and other kinds of tokens aren't allowed to use it.
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if token.ExpiresAt == nil {
+		return nil, nil
+	}
+
+	expiresAt := time.Time(*token.ExpiresAt)
+
+	return &expiresAt, nil
+}
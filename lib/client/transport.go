@@ -0,0 +1,165 @@
+package client
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+//newBaseTransport returns the http transport to the Gitlab API, presenting the client certificate when one is configured.
+func newBaseTransport(certFile string, keyFile string) http.RoundTripper {
+	if certFile == "" || keyFile == "" {
+		return http.DefaultTransport
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		log.Error("Unable to load the client certificate: ", err)
+		return http.DefaultTransport
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.TLSClientConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	return base
+}
+
+//transport wraps the http transport to the Gitlab API to keep track of information from the responses.
+type transport struct {
+	next http.RoundTripper
+
+	//sudo is the user the requests are done as, when set.
+	sudo string
+
+	//slots limits the amount of concurrent requests, when set.
+	slots chan struct{}
+
+	//delay is waited before every request, so every worker is slowed down to reduce the load on Gitlab.
+	delay time.Duration
+
+	mutex              sync.Mutex
+	responses          map[int]int
+	rateLimitSeen      bool
+	rateLimitRemaining float64
+	rateLimitLimit     float64
+
+	//latencies of the requests since they were last taken.
+	latencies []time.Duration
+}
+
+//RoundTrip does the request and records the status code and rate limit headers of the response.
+//When a sudo user is configured, the request is done as that user.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.sudo != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Sudo", t.sudo)
+	}
+
+	// The delay is waited before taking a slot, so waiting workers don't keep others from doing requests.
+	if t.delay > 0 {
+		timer := time.NewTimer(t.delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.release()
+		return resp, err
+	}
+
+	// The slot is kept until the body is read, which is when the request is really finished.
+	if t.slots != nil {
+		resp.Body = &releasingBody{ReadCloser: resp.Body, release: t.release}
+	}
+
+	t.mutex.Lock()
+	if t.responses == nil {
+		t.responses = map[int]int{}
+	}
+	t.responses[resp.StatusCode]++
+	t.latencies = append(t.latencies, time.Since(start))
+	t.mutex.Unlock()
+
+	remaining, remainingErr := strconv.ParseFloat(resp.Header.Get("RateLimit-Remaining"), 64)
+	limit, limitErr := strconv.ParseFloat(resp.Header.Get("RateLimit-Limit"), 64)
+	if remainingErr == nil && limitErr == nil {
+		t.mutex.Lock()
+		t.rateLimitSeen = true
+		t.rateLimitRemaining = remaining
+		t.rateLimitLimit = limit
+		t.mutex.Unlock()
+	}
+
+	return resp, nil
+}
+
+//release frees the slot of a finished request.
+func (t *transport) release() {
+	if t.slots != nil {
+		<-t.slots
+	}
+}
+
+//releasingBody releases the slot of the request once the response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+
+	once    sync.Once
+	release func()
+}
+
+//Close closes the body and releases the slot of the request, only the first time it is called.
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+//responseCounts returns the amount of responses per status code.
+func (t *transport) responseCounts() map[int]int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	result := map[int]int{}
+	for code, count := range t.responses {
+		result[code] = count
+	}
+	return result
+}
+
+//takeLatencies returns the latencies of the requests since the previous call.
+func (t *transport) takeLatencies() []time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	latencies := t.latencies
+	t.latencies = nil
+	return latencies
+}
+
+//rateLimit returns the rate limit of the most recent response that contained rate limit headers.
+func (t *transport) rateLimit() (remaining float64, limit float64, ok bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.rateLimitRemaining, t.rateLimitLimit, t.rateLimitSeen
+}
@@ -0,0 +1,37 @@
+package client
+
+import "sync"
+
+//workers is the amount of concurrent requests done for fetches per project or merge request.
+const workers = 5
+
+//forEach calls fn for every index up to n with a limited amount of workers, and returns the first error that occurred.
+func forEach(n int, fn func(i int) error) error {
+
+	var wg sync.WaitGroup
+
+	errCh := make(chan error, 1)
+	sem := make(chan struct{}, workers)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(i); err != nil {
+				select {
+				case errCh <- err:
+				default:
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	return <-errCh
+}
@@ -2,122 +2,1052 @@
 package collector
 
 import (
+	"regexp"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/whyeasy/gitlab-extra-exporter/internal"
 	client "github.com/whyeasy/gitlab-extra-exporter/lib/client"
 )
 
 //Collector struct for holding Prometheus Desc and Exporter Client
 type Collector struct {
-	up     *prometheus.Desc
-	client *client.ExporterClient
+	//lastHeartbeat is accessed atomically, so it is kept first for the 64-bit alignment on 32-bit platforms.
+	lastHeartbeat int64
+
+	up             *prometheus.Desc
+	scrapeFailures *prometheus.Desc
+	lastScrapeErr  *prometheus.Desc
+	compareSkips   *prometheus.Desc
+	client         *client.ExporterClient
+
+	//families is the name of the metric family of every Desc, which the Desc doesn't expose.
+	families descFamilies
+
+	rateLimitRemaining *prometheus.Desc
+	rateLimitLimit     *prometheus.Desc
+	apiResponses       *prometheus.Desc
+	tokenExpiry        *prometheus.Desc
+	apiLatency         *prometheus.Desc
+	apiLatencyClass    *prometheus.Desc
+	apiLatencyBuckets  *prometheus.Desc
+
+	detailFetchTruncated  *prometheus.Desc
+	cardinalityLimited    *prometheus.Desc
+	detailFetches         *prometheus.Desc
+	mergeErrorSkips       *prometheus.Desc
+	mergeRequestsFiltered *prometheus.Desc
+
+	collectTimeout time.Duration
+	maxSeries      int
+
+	heartbeat *prometheus.Desc
+
+	seriesEmitted        *prometheus.Desc
+	seriesEmittedEnabled bool
+
+	maxTitleLength      int
+	titleRedactPattern  *regexp.Regexp
+	dropTitleLabel      bool
+	dropInternalIDLabel bool
+	groupLabel          bool
+	projectLabel        string
+	groupDepth          int
+	openAgeBuckets      []time.Duration
+	stalenessBasis      string
+	public              bool
+	businessCalendar    *internal.BusinessCalendar
+	latencyThresholds   []time.Duration
+	approvalSLA         time.Duration
+	collectIssues       bool
 
 	projectInfo      *prometheus.Desc
 	mergeRequestInfo *prometheus.Desc
 
+	projectActiveContributors *prometheus.Desc
+	projectCommitAuthors      *prometheus.Desc
+	projectCommits            *prometheus.Desc
+	protectedBranchApprovals  *prometheus.Desc
+	projectApprovalRules      *prometheus.Desc
+	openMergeRequestsAge      *prometheus.Desc
+	projectCIMinutes          *prometheus.Desc
+	projectPipelineStatus     *prometheus.Desc
+	projectLastSuccessAge     *prometheus.Desc
+	projectOpenIssues         *prometheus.Desc
+
+	issueCreated             *prometheus.Desc
+	issueUpdated             *prometheus.Desc
+	projectRequirePipeline   *prometheus.Desc
+	projectOpenMergeRequests *prometheus.Desc
+	projectOpenTargets       *prometheus.Desc
+	projectAvgAssignees      *prometheus.Desc
+	projectOldestUnapproved  *prometheus.Desc
+	projectMergedInWindow    *prometheus.Desc
+	projectTimeInState       *prometheus.Desc
+	projectRepositorySize    *prometheus.Desc
+	projectLastSeen          *prometheus.Desc
+
 	mergeRequestCreated      *prometheus.Desc
 	mergeRequestMerged       *prometheus.Desc
 	mergeRequestClosed       *prometheus.Desc
 	mergeRequestUpdated      *prometheus.Desc
 	mergeRequestChangedFiles *prometheus.Desc
+	mergeRequestPending      *prometheus.Desc
 	mergeRequestAssignees    *prometheus.Desc
+	mergeRequestNoReviewer   *prometheus.Desc
 	mergeRequestDuration     *prometheus.Desc
+	mergeRequestBusiness     *prometheus.Desc
+	mergeRequestUpdates      *prometheus.Desc
+	mergeRequestPickup       *prometheus.Desc
+
+	//The histograms are kept, so their Desc stays the same, and are reset before filling them on every collect.
+	histogramMutex                sync.Mutex
+	mergeRequestDurationHistogram *prometheus.HistogramVec
+	mergeRequestLeadTimeHistogram *prometheus.HistogramVec
+	leadTimeLabels                []string
 
 	//Details for Open Merge Requests
-	mergeRequestApprovals *prometheus.Desc
-	mergeRequestChanges   *prometheus.Desc
+	mergeRequestApprovals     *prometheus.Desc
+	mergeRequestApprovalRules *prometheus.Desc
+	mergeRequestCodeOwnerLeft *prometheus.Desc
+	mergeRequestCodeOwnerRule *prometheus.Desc
+	mergeRequestAwaitingMe    *prometheus.Desc
+	mergeRequestSLABreached   *prometheus.Desc
+	mergeRequestChanges       *prometheus.Desc
+	mergeRequestChangesByType *prometheus.Desc
+	mergeRequestDiffBytes     *prometheus.Desc
+	mergeRequestLabelAdded    *prometheus.Desc
+	mergeRequestLabelRemoved  *prometheus.Desc
+	mergeRequestRebasing      *prometheus.Desc
+	mergeRequestForcePushed   *prometheus.Desc
+	mergeRequestRetries       *prometheus.Desc
+	mergeRequestChangesAsked  *prometheus.Desc
+	mergeRequestReviewRounds  *prometheus.Desc
+	mergeRequestDiscussions   *prometheus.Desc
+
+	//Details for Merged Merge Requests
+	mergeRequestApprovalBypassed *prometheus.Desc
+	mergeRequestFailedPipeline   *prometheus.Desc
+	mergeRequestSelfMerged       *prometheus.Desc
+	mergeRequestReopened         *prometheus.Desc
+
+	authorOpenedMergeRequests *prometheus.Desc
+	authorMergedMergeRequests *prometheus.Desc
+	approverApprovals         *prometheus.Desc
+}
+
+//durationBuckets are the default buckets for merge request durations, ranging from an hour to a month.
+var durationBuckets = []float64{3600, 4 * 3600, 12 * 3600, 24 * 3600, 2 * 24 * 3600, 4 * 24 * 3600, 7 * 24 * 3600, 14 * 24 * 3600, 30 * 24 * 3600}
+
+//descFamilies maps every Desc of the collector to the name of its metric family.
+type descFamilies map[*prometheus.Desc]string
+
+//newDesc creates a Desc like prometheus.NewDesc and records its family name.
+func (f descFamilies) newDesc(fqName, help string, variableLabels []string, constLabels prometheus.Labels) *prometheus.Desc {
+	desc := prometheus.NewDesc(fqName, help, variableLabels, constLabels)
+	f[desc] = fqName
+	return desc
+}
+
+//newHistogramVec creates a HistogramVec and records the family name of its Desc.
+func (f descFamilies) newHistogramVec(opts prometheus.HistogramOpts, labelNames []string) *prometheus.HistogramVec {
+	histogram := prometheus.NewHistogramVec(opts, labelNames)
+
+	descs := make(chan *prometheus.Desc, 1)
+	histogram.Describe(descs)
+	f[<-descs] = prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name)
+
+	return histogram
 }
 
 //New creates a new Collector with Prometheus descriptors.
-func New(c *client.ExporterClient) *Collector {
+func New(c *client.ExporterClient, config internal.Config) *Collector {
 	log.Info("Creating collector")
-	return &Collector{
-		up:     prometheus.NewDesc("gitlab_extra_up", "Whether Gitlab scrap was successful", nil, nil),
-		client: c,
 
-		projectInfo:      prometheus.NewDesc("gitlab_project_info", "General information about projects", []string{"project_id", "project_name"}, nil),
-		mergeRequestInfo: prometheus.NewDesc("gitlab_merge_request_info", "General information about merge requests", []string{"merge_request_id", "target_branch", "source_branch", "state", "merge_request_title", "project_id", "merge_request_internal_id"}, nil),
+	collectTimeout, _ := strconv.ParseInt(config.CollectTimeout, 10, 64)
+	maxTitleLength, _ := strconv.Atoi(config.MaxTitleLength)
+	maxSeries, _ := strconv.Atoi(config.MaxSeries)
+
+	var titleRedactPattern *regexp.Regexp
+	if config.TitleRedactPattern != "" {
+		titleRedactPattern = regexp.MustCompile(config.TitleRedactPattern)
+	}
+	groupDepth, _ := strconv.Atoi(config.GroupDepth)
+	openAgeBuckets, _ := internal.ParseDurations(config.OpenAgeBuckets)
+	latencyThresholds, _ := internal.ParseDurations(config.LatencyThresholds)
+
+	var businessCalendar *internal.BusinessCalendar
+	if config.BusinessHours != "" {
+		businessCalendar, _ = internal.ParseBusinessCalendar(config.BusinessHours, config.BusinessDays, config.BusinessTimezone)
+	}
+	approvalSLA, _ := time.ParseDuration(config.ApprovalSLA)
 
-		mergeRequestUpdated:      prometheus.NewDesc("gitlab_merge_request_updated", "Time since last update on the merge requests that are open", []string{"merge_request_id", "project_id"}, nil),
-		mergeRequestClosed:       prometheus.NewDesc("gitlab_merge_request_closed", "Date of closing the merge request", []string{"merge_request_id", "project_id"}, nil),
-		mergeRequestCreated:      prometheus.NewDesc("gitlab_merge_request_created", "Date of creating the merge request", []string{"merge_request_id", "project_id"}, nil),
-		mergeRequestMerged:       prometheus.NewDesc("gitlab_merge_request_merged", "Date of merging the merge request", []string{"merge_request_id", "project_id"}, nil),
-		mergeRequestChangedFiles: prometheus.NewDesc("gitlab_merge_request_changed_files", "Amount of changed files within the merge request", []string{"merge_request_id", "project_id"}, nil),
-		mergeRequestAssignees:    prometheus.NewDesc("gitlab_merge_request_assignees", "Amount of assignees assigned to the MR", []string{"merge_request_id", "project_id"}, nil),
-		mergeRequestDuration:     prometheus.NewDesc("gitlab_merge_request_duration", "Duration between creating and closing or merging a merge request", []string{"merge_request_id", "project_id"}, nil),
+	buckets := durationBuckets
+	if config.DurationBuckets != "" {
+		buckets, _ = internal.ParseBuckets(config.DurationBuckets)
+	}
+
+	projectInfoLabels := []string{"project_id", "project_name"}
+	if config.GroupLabel {
+		projectInfoLabels = append(projectInfoLabels, "group")
+	}
+	if config.ProjectLabel != "" {
+		projectInfoLabels = append(projectInfoLabels, "project")
+	}
+
+	var leadTimeLabels []string
+	if config.LeadTimePerProject {
+		leadTimeLabels = []string{"project_id"}
+	}
+
+	mergeRequestInfoLabels := []string{"merge_request_id", "target_branch", "source_branch", "state"}
+	if !config.DropTitleLabel {
+		mergeRequestInfoLabels = append(mergeRequestInfoLabels, "merge_request_title")
+	}
+	mergeRequestInfoLabels = append(mergeRequestInfoLabels, "project_id")
+	if config.ProjectLabel != "" {
+		mergeRequestInfoLabels = append(mergeRequestInfoLabels, "project")
+	}
+	if !config.DropInternalIDLabel {
+		mergeRequestInfoLabels = append(mergeRequestInfoLabels, "merge_request_internal_id")
+	}
+
+	families := descFamilies{}
+
+	collector := &Collector{
+		up:             families.newDesc("gitlab_extra_up", "Whether Gitlab scrap was successful", nil, nil),
+		scrapeFailures: families.newDesc("gitlab_extra_scrape_failures_total", "Amount of background scrapes of Gitlab that failed", nil, nil),
+		lastScrapeErr:  families.newDesc("gitlab_extra_last_scrape_error", "Stage and reason of the most recent background scrape when it failed", []string{"stage", "reason"}, nil),
+		compareSkips:   families.newDesc("gitlab_extra_compare_skipped_total", "Amount of merge requests of which the changes were skipped because a compared branch didn't exist", nil, nil),
+		client:         c,
+		families:       families,
+
+		rateLimitRemaining: families.newDesc("gitlab_extra_ratelimit_remaining", "Amount of requests left within the Gitlab rate limit, as reported on the most recent API response", nil, nil),
+		rateLimitLimit:     families.newDesc("gitlab_extra_ratelimit_limit", "Rate limit of the Gitlab API, as reported on the most recent API response", nil, nil),
+		apiResponses:       families.newDesc("gitlab_extra_api_responses_total", "Amount of responses of the Gitlab API per status code", []string{"code"}, nil),
+		tokenExpiry:        families.newDesc("gitlab_extra_token_expiry_timestamp", "Unix timestamp at which the API key of the exporter expires", nil, nil),
+		apiLatency:         families.newDesc("gitlab_extra_api_latency_seconds", "Latency quantile of the Gitlab API during the most recent background scrape", []string{"quantile"}, nil),
+		apiLatencyClass:    families.newDesc("gitlab_extra_api_latency_class", "Class of the 95th percentile latency of the Gitlab API during the most recent background scrape, fast, normal or slow", []string{"class"}, nil),
+		apiLatencyBuckets:  families.newDesc("gitlab_extra_api_request_duration_seconds", "Latency of the Gitlab API requests of the background scrapes", nil, nil),
+
+		mergeRequestsFiltered: families.newDesc("gitlab_extra_merge_requests_filtered", "Amount of merge requests within the window that are left out by a filter", []string{"reason"}, nil),
+		detailFetches:         families.newDesc("gitlab_extra_detail_fetches_total", "Amount of merge requests of which the details were retrieved, per state", []string{"state"}, nil),
+		mergeErrorSkips:       families.newDesc("gitlab_extra_merge_error_skipped_total", "Amount of merged and closed merge requests that were left out because Gitlab reported a merge error, per state", []string{"state"}, nil),
+		detailFetchTruncated:  families.newDesc("gitlab_extra_detail_fetch_truncated", "Whether the details of merge requests were only retrieved for the most recently updated ones", nil, nil),
+		cardinalityLimited:    families.newDesc("gitlab_extra_cardinality_limited", "Whether the metrics per merge request were left out because they exceeded the maximum amount of series", nil, nil),
+
+		collectTimeout: time.Duration(collectTimeout) * time.Second,
+		maxSeries:      maxSeries,
+		heartbeat:      families.newDesc("gitlab_extra_heartbeat_timestamp", "Unix timestamp of the most recent heartbeat of the exporter, which lags when the exporter is stuck", nil, nil),
+
+		seriesEmitted:        families.newDesc("gitlab_extra_series_emitted", "Amount of metrics sent per metric family during this scrape, a histogram counts as one", []string{"family"}, nil),
+		seriesEmittedEnabled: config.SeriesMetrics,
+
+		maxTitleLength:      maxTitleLength,
+		titleRedactPattern:  titleRedactPattern,
+		dropTitleLabel:      config.DropTitleLabel,
+		dropInternalIDLabel: config.DropInternalIDLabel,
+		groupLabel:          config.GroupLabel,
+		projectLabel:        config.ProjectLabel,
+		groupDepth:          groupDepth,
+		openAgeBuckets:      openAgeBuckets,
+		stalenessBasis:      config.StalenessBasis,
+		businessCalendar:    businessCalendar,
+		latencyThresholds:   latencyThresholds,
+		approvalSLA:         approvalSLA,
+		collectIssues:       config.CollectIssues,
+
+		projectInfo:      families.newDesc("gitlab_project_info", "General information about projects", projectInfoLabels, nil),
+		mergeRequestInfo: families.newDesc("gitlab_merge_request_info", "General information about merge requests", mergeRequestInfoLabels, nil),
+
+		projectActiveContributors: families.newDesc("gitlab_project_active_contributors", "Amount of distinct authors of merge requests within the project", []string{"project_id"}, nil),
+		projectCommitAuthors:      families.newDesc("gitlab_project_commit_authors", "Amount of distinct authors of commits on the default branch of the project within the window, by email", []string{"project_id"}, nil),
+		projectCommits:            families.newDesc("gitlab_project_commits", "Amount of commits on the default branch of the project within the window", []string{"project_id"}, nil),
+		protectedBranchApprovals:  families.newDesc("gitlab_project_protected_branch_approvals_required", "Amount of approvals required by the approval rules scoped to the protected target branch", []string{"project_id", "branch"}, nil),
+		projectApprovalRules:      families.newDesc("gitlab_project_approval_rules_count", "Amount of approval rules configured on the project", []string{"project_id"}, nil),
+		openMergeRequestsAge:      families.newDesc("gitlab_open_merge_requests_age_bucket", "Amount of open merge requests within the project per age bucket", []string{"project_id", "bucket"}, nil),
+		projectOpenMergeRequests:  families.newDesc("gitlab_project_open_merge_requests_count", "Amount of open merge requests within the project", []string{"project_id", "project_name"}, nil),
+		projectAvgAssignees:       families.newDesc("gitlab_project_avg_assignees_open_mr", "Average amount of assignees of the open merge requests within the project", []string{"project_id"}, nil),
+		projectOldestUnapproved:   families.newDesc("gitlab_project_oldest_unapproved_mr_age_seconds", "Age in seconds of the oldest open merge request with approvals left within the project", []string{"project_id"}, nil),
+		projectMergedInWindow:     families.newDesc("gitlab_project_merged_merge_requests_window", "Amount of merge requests merged within the window in the project", []string{"project_id", "project_name"}, nil),
+		projectOpenTargets:        families.newDesc("gitlab_project_open_target_branches", "Amount of distinct target branches of the open merge requests within the project", []string{"project_id"}, nil),
+		projectTimeInState:        families.newDesc("gitlab_project_avg_time_in_state_seconds", "Average time the merged merge requests of the project spent in the state", []string{"project_id", "state"}, nil),
+		projectRequirePipeline:    families.newDesc("gitlab_project_require_pipeline_success", "Whether the project only allows merging when the pipeline succeeded", []string{"project_id"}, nil),
+		projectPipelineStatus:     families.newDesc("gitlab_project_pipeline_status", "Status of the latest pipeline on the default branch of the project", []string{"project_id", "status"}, nil),
+		projectOpenIssues:         families.newDesc("gitlab_project_open_issues", "Amount of open issues within the project", []string{"project_id"}, nil),
+		projectLastSuccessAge:     families.newDesc("gitlab_project_last_successful_pipeline_age_seconds", "Time since the latest successful pipeline on the default branch of the project", []string{"project_id"}, nil),
+		projectRepositorySize:     families.newDesc("gitlab_project_repository_size_bytes", "Size of the repository of the project in bytes", []string{"project_id"}, nil),
+		projectLastSeen:           families.newDesc("gitlab_project_last_seen_timestamp", "Unix timestamp of the most recent background scrape that listed the project", []string{"project_id"}, nil),
+		projectCIMinutes:          families.newDesc("gitlab_project_ci_minutes", "CI minutes consumed by the jobs of the project", []string{"project_id"}, nil),
+
+		issueCreated: families.newDesc("gitlab_issue_created", "Created timestamp of the open issue", []string{"issue_id", "project_id"}, nil),
+		issueUpdated: families.newDesc("gitlab_issue_updated", "Last update timestamp of the open issue", []string{"issue_id", "project_id"}, nil),
+
+		mergeRequestUpdated:      families.newDesc("gitlab_merge_request_updated", "Time since last update on the merge requests that are open", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestClosed:       families.newDesc("gitlab_merge_request_closed", "Date of closing the merge request", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestCreated:      families.newDesc("gitlab_merge_request_created", "Date of creating the merge request", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestMerged:       families.newDesc("gitlab_merge_request_merged", "Date of merging the merge request", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestChangedFiles: families.newDesc("gitlab_merge_request_changed_files", "Amount of changed files within the merge request", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestPending:      families.newDesc("gitlab_merge_request_changes_pending", "Whether Gitlab is still computing the changes of the open merge request, its changed files are left out", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestAssignees:    families.newDesc("gitlab_merge_request_assignees", "Amount of assignees assigned to the MR", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestNoReviewer:   families.newDesc("gitlab_merge_request_no_reviewer", "Whether no reviewer is assigned to the open MR", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestDuration:     families.newDesc("gitlab_merge_request_duration", "Duration between creating and closing or merging a merge request", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestBusiness:     families.newDesc("gitlab_merge_request_business_duration_seconds", "Duration between creating and merging a merge request within the working hours on working days", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestUpdates:      families.newDesc("gitlab_merge_request_updates_total", "Amount of times the merge request was updated between scrapes", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestPickup:       families.newDesc("gitlab_merge_request_pickup_seconds", "Time between creating the merge request and requesting the first review", []string{"merge_request_id", "project_id"}, nil),
+
+		mergeRequestDurationHistogram: families.newHistogramVec(prometheus.HistogramOpts{
+			Name:    "gitlab_merge_request_duration_seconds",
+			Help:    "Distribution of the duration between creating and closing or merging a merge request",
+			Buckets: buckets,
+		}, []string{"state"}),
+		mergeRequestLeadTimeHistogram: families.newHistogramVec(prometheus.HistogramOpts{
+			Name:    "gitlab_merge_request_lead_time_seconds",
+			Help:    "Distribution of the duration between creating and merging a merge request",
+			Buckets: buckets,
+		}, leadTimeLabels),
+		leadTimeLabels: leadTimeLabels,
 
 		//Details for Open Merge Requests
-		mergeRequestApprovals: prometheus.NewDesc("gitlab_merge_request_approvals", "Amount of approvals left for approving MR", []string{"merge_request_id", "project_id"}, nil),
-		mergeRequestChanges:   prometheus.NewDesc("gitlab_merge_request_changes", "Amount of additions and deletions within the merge request", []string{"merge_request_id", "project_id", "lines"}, nil),
+		mergeRequestApprovals:     families.newDesc("gitlab_merge_request_approvals", "Amount of approvals left for approving MR", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestApprovalRules: families.newDesc("gitlab_merge_request_approval_rule", "Amount of approvals required by the approval rule of the MR, 0 for optional rules", []string{"merge_request_id", "project_id", "rule_name", "rule_type"}, nil),
+		mergeRequestCodeOwnerLeft: families.newDesc("gitlab_merge_request_codeowner_approvals_left", "Amount of approvals left for the code owner rules of the merge request", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestCodeOwnerRule: families.newDesc("gitlab_merge_request_codeowner_rule_satisfied", "Whether the required code owners of the code owner rule approved the merge request", []string{"merge_request_id", "project_id", "rule_name"}, nil),
+		mergeRequestAwaitingMe:    families.newDesc("gitlab_merge_request_awaiting_my_approval", "Whether the merge request awaits the approval of the user of the token", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestSLABreached:   families.newDesc("gitlab_merge_request_approval_sla_breached", "Whether the open merge request has approvals left and was created longer than the approval SLA ago", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestChanges:       families.newDesc("gitlab_merge_request_changes", "Amount of additions and deletions within the merge request", []string{"merge_request_id", "project_id", "lines"}, nil),
+		mergeRequestChangesByType: families.newDesc("gitlab_merge_request_changes_by_type", "Amount of additions and deletions within the merge request to files of the tracked extension", []string{"merge_request_id", "project_id", "extension", "lines"}, nil),
+		mergeRequestDiffBytes:     families.newDesc("gitlab_merge_request_diff_bytes", "Size in bytes of the diffs of the merge request", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestRebasing:      families.newDesc("gitlab_merge_request_rebase_in_progress", "Whether a rebase of the open merge request is in progress", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestForcePushed:   families.newDesc("gitlab_merge_request_forcepushed_after_approval", "Whether the source branch of the approved merge request was force-pushed after the last approval", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestRetries:       families.newDesc("gitlab_merge_request_pipeline_retries", "Amount of pipelines on the source branch of the open merge request that ran after a failed pipeline for the same commit", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestChangesAsked:  families.newDesc("gitlab_merge_request_changes_requested", "Amount of reviewers of which the latest review requested changes on the merge request", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestReviewRounds:  families.newDesc("gitlab_merge_request_review_rounds", "Estimated amount of review rounds on the merge request, comments of others followed by a response of the author", []string{"merge_request_id", "project_id"}, nil),
+		mergeRequestDiscussions:   families.newDesc("gitlab_merge_request_discussions", "Amount of discussions on the merge request, by whether the author or a reviewer started them", []string{"merge_request_id", "project_id", "initiator"}, nil),
+		mergeRequestLabelAdded:    families.newDesc("gitlab_merge_request_label_added_total", "Amount of times the tracked label was added to the merge request", []string{"merge_request_id", "project_id", "label"}, nil),
+		mergeRequestLabelRemoved:  families.newDesc("gitlab_merge_request_label_removed_total", "Amount of times the tracked label was removed from the merge request", []string{"merge_request_id", "project_id", "label"}, nil),
+
+		//Details for Merged Merge Requests
+		mergeRequestApprovalBypassed: families.newDesc("gitlab_merge_request_approval_bypassed", "Amount of merged merge requests that still had approvals left", []string{"project_id"}, nil),
+		mergeRequestFailedPipeline:   families.newDesc("gitlab_merge_request_merged_with_failed_pipeline", "Amount of merged merge requests of which the head pipeline failed or was skipped", []string{"project_id", "status"}, nil),
+		mergeRequestSelfMerged:       families.newDesc("gitlab_merge_request_self_merged", "Amount of merged merge requests that were merged by their author", []string{"project_id"}, nil),
+		mergeRequestReopened:         families.newDesc("gitlab_merge_request_reopened", "Amount of merge requests within the window that were reopened after being closed within the window", []string{"project_id"}, nil),
+
+		authorOpenedMergeRequests: families.newDesc("gitlab_author_opened_merge_requests", "Amount of merge requests of the author within the window, in any state", []string{"username"}, nil),
+		authorMergedMergeRequests: families.newDesc("gitlab_author_merged_merge_requests", "Amount of merged merge requests of the author within the window", []string{"username"}, nil),
+		approverApprovals:         families.newDesc("gitlab_approver_approvals", "Amount of approvals the approver gave on merge requests within the window", []string{"username"}, nil),
+	}
+
+	go collector.beat()
+
+	return collector
+}
+
+//NewPublic creates a Collector for a broad audience, without the merge request titles and the metrics per username.
+//It exports the same cached stats as the Collector of New.
+func NewPublic(c *client.ExporterClient, config internal.Config) *Collector {
+	config.DropTitleLabel = true
+
+	collector := New(c, config)
+	collector.public = true
+
+	return collector
+}
+
+//heartbeatInterval is how often the heartbeat timestamp is updated.
+const heartbeatInterval = 5 * time.Second
+
+//beat updates the heartbeat timestamp for the lifetime of the exporter, independent of scrapes of Gitlab and Prometheus.
+func (c *Collector) beat() {
+	atomic.StoreInt64(&c.lastHeartbeat, time.Now().Unix())
+
+	for now := range time.Tick(heartbeatInterval) {
+		atomic.StoreInt64(&c.lastHeartbeat, now.Unix())
 	}
 }
 
 //Describe the metrics that are collected.
 func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.up
+	ch <- c.heartbeat
+	ch <- c.scrapeFailures
+	ch <- c.lastScrapeErr
+	ch <- c.compareSkips
+	ch <- c.tokenExpiry
+	ch <- c.rateLimitRemaining
+	ch <- c.rateLimitLimit
+	ch <- c.apiResponses
+	ch <- c.apiLatency
+	ch <- c.apiLatencyClass
+	ch <- c.apiLatencyBuckets
+	ch <- c.detailFetchTruncated
+	ch <- c.cardinalityLimited
+	ch <- c.detailFetches
+	ch <- c.mergeErrorSkips
+	ch <- c.mergeRequestsFiltered
+	ch <- c.seriesEmitted
 
 	ch <- c.projectInfo
 	ch <- c.mergeRequestInfo
 
+	ch <- c.projectActiveContributors
+	ch <- c.projectCommitAuthors
+	ch <- c.projectCommits
+	ch <- c.protectedBranchApprovals
+	ch <- c.projectApprovalRules
+	ch <- c.openMergeRequestsAge
+	ch <- c.projectCIMinutes
+	ch <- c.projectPipelineStatus
+	ch <- c.projectOpenIssues
+	ch <- c.issueCreated
+	ch <- c.issueUpdated
+	ch <- c.projectLastSuccessAge
+	ch <- c.projectRequirePipeline
+	ch <- c.projectOpenMergeRequests
+	ch <- c.projectOpenTargets
+	ch <- c.projectAvgAssignees
+	ch <- c.projectOldestUnapproved
+	ch <- c.projectMergedInWindow
+	ch <- c.projectTimeInState
+	ch <- c.projectRepositorySize
+	ch <- c.projectLastSeen
+
 	ch <- c.mergeRequestUpdated
 	ch <- c.mergeRequestChangedFiles
+	ch <- c.mergeRequestPending
 	ch <- c.mergeRequestClosed
 	ch <- c.mergeRequestCreated
 	ch <- c.mergeRequestMerged
 	ch <- c.mergeRequestAssignees
+	ch <- c.mergeRequestNoReviewer
 	ch <- c.mergeRequestDuration
+	ch <- c.mergeRequestBusiness
+	ch <- c.mergeRequestUpdates
+	ch <- c.mergeRequestPickup
+	c.mergeRequestDurationHistogram.Describe(ch)
+	c.mergeRequestLeadTimeHistogram.Describe(ch)
 
 	//Details for Open Merge Requests
 	ch <- c.mergeRequestApprovals
+	ch <- c.mergeRequestSLABreached
+	ch <- c.mergeRequestApprovalRules
+	ch <- c.mergeRequestCodeOwnerLeft
+	ch <- c.mergeRequestCodeOwnerRule
+	ch <- c.mergeRequestAwaitingMe
 	ch <- c.mergeRequestChanges
+	ch <- c.mergeRequestChangesByType
+	ch <- c.mergeRequestDiffBytes
+	ch <- c.mergeRequestLabelAdded
+	ch <- c.mergeRequestLabelRemoved
+	ch <- c.mergeRequestRebasing
+	ch <- c.mergeRequestForcePushed
+	ch <- c.mergeRequestRetries
+	ch <- c.mergeRequestChangesAsked
+	ch <- c.mergeRequestReviewRounds
+	ch <- c.mergeRequestDiscussions
+
+	//Details for Merged Merge Requests
+	ch <- c.mergeRequestApprovalBypassed
+	ch <- c.mergeRequestFailedPipeline
+	ch <- c.mergeRequestSelfMerged
+	ch <- c.mergeRequestReopened
+
+	ch <- c.authorOpenedMergeRequests
+	ch <- c.authorMergedMergeRequests
+	ch <- c.approverApprovals
 }
 
 //Collect gathers the metrics that are exported.
+//When collecting takes longer than the collect timeout, the metrics gathered so far are returned with up set to 0.
 func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 
 	log.Info("Running scrape")
 
-	if stats, err := c.client.GetStats(); err != nil {
+	ch <- prometheus.MustNewConstMetric(c.heartbeat, prometheus.GaugeValue, float64(atomic.LoadInt64(&c.lastHeartbeat)))
+
+	metrics := make(chan prometheus.Metric)
+	success := make(chan bool, 1)
+
+	go func() {
+		success <- c.collect(metrics)
+		close(metrics)
+	}()
+
+	var timeout <-chan time.Time
+	if c.collectTimeout > 0 {
+		timeout = time.After(c.collectTimeout)
+	}
+
+	emitted := map[*prometheus.Desc]int{}
+
+	for {
+		select {
+		case metric, ok := <-metrics:
+			if !ok {
+				up := 0.0
+				if <-success {
+					up = 1
+				}
+				c.collectSeriesEmitted(ch, emitted)
+				ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, up)
+				return
+			}
+			emitted[metric.Desc()]++
+			ch <- metric
+		case <-timeout:
+			log.Warn("Collecting metrics took longer than ", c.collectTimeout, ", returning partial results")
+
+			// Drain the remaining metrics so the collecting goroutine can finish.
+			go func() {
+				for range metrics {
+				}
+			}()
+
+			c.collectSeriesEmitted(ch, emitted)
+			ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0)
+			return
+		}
+	}
+}
+
+//collectSeriesEmitted sends the amount of metrics sent per family during this collect, when enabled.
+func (c *Collector) collectSeriesEmitted(ch chan<- prometheus.Metric, emitted map[*prometheus.Desc]int) {
+	if !c.seriesEmittedEnabled {
+		return
+	}
+
+	families := map[string]int{}
+	for desc, count := range emitted {
+		if family, ok := c.families[desc]; ok {
+			families[family] += count
+		}
+	}
+
+	for family, count := range families {
+		ch <- prometheus.MustNewConstMetric(c.seriesEmitted, prometheus.GaugeValue, float64(count), family)
+	}
+}
+
+//collect sends all metrics based on the cached stats, and returns whether retrieving the stats succeeded.
+func (c *Collector) collect(ch chan<- prometheus.Metric) bool {
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeFailures, prometheus.CounterValue, float64(c.client.ScrapeFailures()))
+
+	if scrapeErr := c.client.LastScrapeError(); scrapeErr != nil {
+		ch <- prometheus.MustNewConstMetric(c.lastScrapeErr, prometheus.GaugeValue, 1, scrapeErr.Stage, scrapeErr.Reason)
+	}
+	ch <- prometheus.MustNewConstMetric(c.compareSkips, prometheus.CounterValue, float64(c.client.CompareSkips()))
+
+	for state, count := range c.client.DetailFetches() {
+		ch <- prometheus.MustNewConstMetric(c.detailFetches, prometheus.CounterValue, float64(count), state)
+	}
+
+	for state, count := range c.client.MergeErrorSkips() {
+		ch <- prometheus.MustNewConstMetric(c.mergeErrorSkips, prometheus.CounterValue, float64(count), state)
+	}
+
+	for project, seen := range c.client.ProjectsLastSeen() {
+		ch <- prometheus.MustNewConstMetric(c.projectLastSeen, prometheus.GaugeValue, float64(seen.Unix()), project)
+	}
+
+	for code, count := range c.client.APIResponses() {
+		ch <- prometheus.MustNewConstMetric(c.apiResponses, prometheus.CounterValue, float64(count), strconv.Itoa(code))
+	}
+
+	if p50, p95, ok := c.client.APILatency(); ok {
+		ch <- prometheus.MustNewConstMetric(c.apiLatency, prometheus.GaugeValue, p50.Seconds(), "0.5")
+		ch <- prometheus.MustNewConstMetric(c.apiLatency, prometheus.GaugeValue, p95.Seconds(), "0.95")
+		ch <- prometheus.MustNewConstMetric(c.apiLatencyClass, prometheus.GaugeValue, 1, latencyClass(p95, c.latencyThresholds))
+	}
+
+	count, sum, buckets := c.client.APILatencyHistogram()
+	ch <- prometheus.MustNewConstHistogram(c.apiLatencyBuckets, count, sum, buckets)
+
+	if remaining, limit, ok := c.client.RateLimit(); ok {
+		ch <- prometheus.MustNewConstMetric(c.rateLimitRemaining, prometheus.GaugeValue, remaining)
+		ch <- prometheus.MustNewConstMetric(c.rateLimitLimit, prometheus.GaugeValue, limit)
+	}
+
+	stats, err := c.client.GetStats()
+	if err != nil {
 		log.Error(err)
-		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0)
-	} else {
-		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 1)
+		return false
+	}
+
+	truncated := 0.0
+	if stats.DetailFetchTruncated {
+		truncated = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.detailFetchTruncated, prometheus.GaugeValue, truncated)
+
+	if stats.TokenExpiresAt != nil {
+		ch <- prometheus.MustNewConstMetric(c.tokenExpiry, prometheus.GaugeValue, float64(stats.TokenExpiresAt.Unix()))
+	}
+
+	for _, filtered := range *stats.Filtered {
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestsFiltered, prometheus.GaugeValue, float64(filtered.Count), filtered.Reason)
+	}
+
+	collectProjectInfo(c, ch, stats)
+
+	collectProjectActiveContributors(c, ch, stats)
+
+	collectProjectCommits(c, ch, stats)
+	collectProtectedBranchApprovals(c, ch, stats)
+	collectProjectApprovalRules(c, ch, stats)
+
+	collectOpenMergeRequestsAge(c, ch, stats)
+
+	collectProjectOpenMergeRequests(c, ch, stats)
+
+	collectProjectOpenTargetBranches(c, ch, stats)
 
-		collectProjectInfo(c, ch, stats)
+	collectProjectAvgAssignees(c, ch, stats)
+	collectProjectOldestUnapproved(c, ch, stats)
+	collectProjectMergedInWindow(c, ch, stats)
 
-		collectMergeReqeustInfo(c, ch, stats)
+	collectProjectTimeInState(c, ch, stats)
 
-		collectOpenMergeRequestMetrics(c, ch, stats)
+	collectProjectCIMinutes(c, ch, stats)
 
-		collectClosedMergeRequestMetrics(c, ch, stats)
+	collectProjectPipelineStatus(c, ch, stats)
 
-		collectMergedMergeRequestMetrics(c, ch, stats)
+	collectProjectIssues(c, ch, stats)
 
-		collectMergeRequestApprovalMetrics(c, ch, stats)
+	collectMergeRequestApprovalBypassed(c, ch, stats)
 
-		collectMergeRequestChanges(c, ch, stats)
+	collectMergeRequestFailedPipelines(c, ch, stats)
 
-		log.Info("Scrape Complete")
+	collectMergeRequestSelfMerged(c, ch, stats)
+
+	collectMergeRequestReopened(c, ch, stats)
+
+	collectAuthorMergeRequests(c, ch, stats)
+
+	collectApproverApprovals(c, ch, stats)
+
+	c.collectMergeRequestSeries(ch, stats)
+
+	log.Info("Scrape Complete")
+
+	return true
+}
+
+//latencyClass classifies the 95th percentile API latency with the fast and slow thresholds.
+func latencyClass(p95 time.Duration, thresholds []time.Duration) string {
+	switch {
+	case len(thresholds) != 2:
+		return "normal"
+	case p95 < thresholds[0]:
+		return "fast"
+	case p95 >= thresholds[1]:
+		return "slow"
+	default:
+		return "normal"
 	}
+}
 
+//mergeRequestCollectors send the metrics with a series per merge request, which are left out when maxSeries is exceeded.
+//The histograms of the merge requests are included as well, so the maximum covers all metrics derived from the individual merge requests.
+var mergeRequestCollectors = []func(*Collector, chan<- prometheus.Metric, *client.Stats){
+	collectMergeReqeustInfo,
+	collectOpenMergeRequestMetrics,
+	collectClosedMergeRequestMetrics,
+	collectMergedMergeRequestMetrics,
+	collectMergeRequestDurationHistogram,
+	collectMergeRequestLeadTimeHistogram,
+	collectMergeRequestApprovalMetrics,
+	collectMergeRequestApprovalSLA,
+	collectMergeRequestChanges,
+	collectMergeRequestLabelEvents,
+	collectMergeRequestUpdates,
+	collectMergeRequestForcePushes,
+	collectMergeRequestPipelineRetries,
+	collectMergeRequestChangesRequested,
+	collectMergeRequestReviewRounds,
+	collectMergeRequestDiscussions,
+	collectMergeRequestPickups,
+}
+
+//collectMergeRequestSeries sends the metrics per merge request, when there is a maxSeries they are buffered first to check the amount of series.
+func (c *Collector) collectMergeRequestSeries(ch chan<- prometheus.Metric, stats *client.Stats) {
+	if c.maxSeries == 0 {
+		for _, collectFn := range mergeRequestCollectors {
+			collectFn(c, ch, stats)
+		}
+		return
+	}
+
+	buffer := make(chan prometheus.Metric)
+	done := make(chan struct{})
+
+	var metrics []prometheus.Metric
+	go func() {
+		for metric := range buffer {
+			metrics = append(metrics, metric)
+		}
+		close(done)
+	}()
+
+	for _, collectFn := range mergeRequestCollectors {
+		collectFn(c, buffer, stats)
+	}
+	close(buffer)
+	<-done
+
+	limited := 0.0
+	if len(metrics) > c.maxSeries {
+		log.Warn("Leaving out the metrics per merge request, ", len(metrics), " series exceed the maximum of ", c.maxSeries)
+		limited = 1
+	} else {
+		for _, metric := range metrics {
+			ch <- metric
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(c.cardinalityLimited, prometheus.GaugeValue, limited)
 }
 
 func collectProjectInfo(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
 	for _, project := range *stats.Projects {
-		ch <- prometheus.MustNewConstMetric(c.projectInfo, prometheus.GaugeValue, 1, project.ID, project.PathWithNamespace)
+		labels := []string{project.ID, project.PathWithNamespace}
+		if c.groupLabel {
+			labels = append(labels, projectGroup(project.PathWithNamespace, c.groupDepth))
+		}
+		if c.projectLabel != "" {
+			labels = append(labels, shortProjectName(project.PathWithNamespace, c.projectLabel))
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.projectInfo, prometheus.GaugeValue, 1, labels...)
+
+		requirePipeline := 0.0
+		if project.RequirePipelineSuccess {
+			requirePipeline = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.projectRequirePipeline, prometheus.GaugeValue, requirePipeline, project.ID)
+
+		if project.RepositorySize != nil {
+			ch <- prometheus.MustNewConstMetric(c.projectRepositorySize, prometheus.GaugeValue, float64(*project.RepositorySize), project.ID)
+		}
+	}
+}
+
+//projectGroup returns the first depth components of the namespace of the project path.
+func projectGroup(pathWithNamespace string, depth int) string {
+	components := strings.Split(pathWithNamespace, "/")
+	namespace := components[:len(components)-1]
+	if len(namespace) > depth {
+		namespace = namespace[:depth]
 	}
+	return strings.Join(namespace, "/")
+}
+
+//slugPattern matches the runs of characters that are replaced by a dash in a slug.
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+//shortProjectName returns the final component of the project path for the form name, or the whole path in lower case with dashes for the form slug.
+func shortProjectName(pathWithNamespace string, form string) string {
+	if form == "slug" {
+		return strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(pathWithNamespace), "-"), "-")
+	}
+	return pathWithNamespace[strings.LastIndex(pathWithNamespace, "/")+1:]
 }
 
 func collectMergeReqeustInfo(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	projects := map[string]string{}
+	for _, project := range *stats.Projects {
+		projects[project.ID] = project.PathWithNamespace
+	}
+
 	for _, mr := range *stats.MergeRequests {
-		ch <- prometheus.MustNewConstMetric(c.mergeRequestInfo, prometheus.GaugeValue, 1, mr.ID, mr.TargetBranch, mr.SourceBranch, mr.State, mr.Title, mr.ProjectID, strconv.Itoa(mr.InternalID))
+		labels := []string{mr.ID, mr.TargetBranch, mr.SourceBranch, mr.State}
+		if !c.dropTitleLabel {
+			labels = append(labels, truncateTitle(redactTitle(mr.Title, c.titleRedactPattern), c.maxTitleLength))
+		}
+		labels = append(labels, mr.ProjectID)
+		if c.projectLabel != "" {
+			labels = append(labels, shortProjectName(projects[mr.ProjectID], c.projectLabel))
+		}
+		if !c.dropInternalIDLabel {
+			labels = append(labels, strconv.Itoa(mr.InternalID))
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestInfo, prometheus.GaugeValue, 1, labels...)
+	}
+}
+
+//redactedTitle replaces the titles that match the redact pattern.
+const redactedTitle = "[redacted]"
+
+//redactTitle replaces the whole title when it matches the pattern, a nil pattern keeps every title.
+func redactTitle(title string, pattern *regexp.Regexp) string {
+	if pattern != nil && pattern.MatchString(title) {
+		return redactedTitle
+	}
+	return title
+}
+
+//truncateTitle shortens the title to maxLength characters with an ellipsis, a maxLength of 0 keeps the full title.
+func truncateTitle(title string, maxLength int) string {
+	runes := []rune(title)
+	if maxLength <= 0 || len(runes) <= maxLength {
+		return title
+	}
+	if maxLength == 1 {
+		return "…"
+	}
+	return string(runes[:maxLength-1]) + "…"
+}
+
+func collectProjectActiveContributors(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	contributors := map[string]map[string]bool{}
+	addContributor := func(projectID, author string) {
+		if author == "" {
+			return
+		}
+		if _, ok := contributors[projectID]; !ok {
+			contributors[projectID] = map[string]bool{}
+		}
+		contributors[projectID][author] = true
+	}
+
+	for _, mr := range *stats.MergeRequests {
+		addContributor(mr.ProjectID, mr.Author)
+	}
+
+	for projectID, authors := range contributors {
+		ch <- prometheus.MustNewConstMetric(c.projectActiveContributors, prometheus.GaugeValue, float64(len(authors)), projectID)
+	}
+
+	// Commit authors are identified by their email instead of their username, so they are exported separately from the MR authors.
+	for _, commits := range *stats.CommitAuthors {
+		ch <- prometheus.MustNewConstMetric(c.projectCommitAuthors, prometheus.GaugeValue, float64(len(commits.Authors)), commits.ProjectID)
+	}
+}
+
+func collectProjectCIMinutes(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	for _, minutes := range *stats.CIMinutes {
+		ch <- prometheus.MustNewConstMetric(c.projectCIMinutes, prometheus.GaugeValue, minutes.Minutes, minutes.ProjectID)
+	}
+}
+
+func collectProjectPipelineStatus(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	for _, pipeline := range *stats.PipelineStatuses {
+		ch <- prometheus.MustNewConstMetric(c.projectPipelineStatus, prometheus.GaugeValue, 1, pipeline.ProjectID, pipeline.Status)
+
+		if pipeline.LastSuccessAt != nil {
+			ch <- prometheus.MustNewConstMetric(c.projectLastSuccessAge, prometheus.GaugeValue, time.Since(*pipeline.LastSuccessAt).Seconds(), pipeline.ProjectID)
+		}
+	}
+}
+
+//collectProjectIssues exports the amount of open issues of every project, including the projects without open issues, and when each open issue was created and updated.
+func collectProjectIssues(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	if !c.collectIssues {
+		return
+	}
+
+	open := map[string]int{}
+	for _, issue := range *stats.Issues {
+		open[issue.ProjectID]++
+
+		if issue.CreatedAt != nil {
+			ch <- prometheus.MustNewConstMetric(c.issueCreated, prometheus.GaugeValue, float64(issue.CreatedAt.Unix()), issue.ID, issue.ProjectID)
+		}
+		if issue.UpdatedAt != nil {
+			ch <- prometheus.MustNewConstMetric(c.issueUpdated, prometheus.GaugeValue, float64(issue.UpdatedAt.Unix()), issue.ID, issue.ProjectID)
+		}
+	}
+
+	for _, project := range *stats.Projects {
+		ch <- prometheus.MustNewConstMetric(c.projectOpenIssues, prometheus.GaugeValue, float64(open[project.ID]), project.ID)
+	}
+}
+
+func collectProjectOpenMergeRequests(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	open := map[string]int{}
+	for _, mr := range *stats.MergeRequestsOpen {
+		open[mr.ProjectID]++
+	}
+
+	for _, project := range *stats.Projects {
+		ch <- prometheus.MustNewConstMetric(c.projectOpenMergeRequests, prometheus.GaugeValue, float64(open[project.ID]), project.ID, project.PathWithNamespace)
+	}
+}
+
+//collectProjectMergedInWindow counts the MRs merged within the window per project, retained MRs merged before the window are left out.
+func collectProjectMergedInWindow(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	windowStart := time.Now().Add(-client.Window)
+
+	merged := map[string]int{}
+	for _, mr := range *stats.MergeRequestsMerged {
+		if mr.MergedAt != nil && mr.MergedAt.After(windowStart) {
+			merged[mr.MergeRequest.ProjectID]++
+		}
+	}
+
+	for _, project := range *stats.Projects {
+		ch <- prometheus.MustNewConstMetric(c.projectMergedInWindow, prometheus.GaugeValue, float64(merged[project.ID]), project.ID, project.PathWithNamespace)
+	}
+}
+
+func collectProjectOpenTargetBranches(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	targets := map[string]map[string]bool{}
+	for _, mr := range *stats.MergeRequestsOpen {
+		if _, ok := targets[mr.ProjectID]; !ok {
+			targets[mr.ProjectID] = map[string]bool{}
+		}
+		targets[mr.ProjectID][mr.TargetBranch] = true
+	}
+
+	for projectID, branches := range targets {
+		ch <- prometheus.MustNewConstMetric(c.projectOpenTargets, prometheus.GaugeValue, float64(len(branches)), projectID)
+	}
+}
+
+func collectProjectAvgAssignees(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	open := map[string]int{}
+	assignees := map[string]int{}
+	for _, mr := range *stats.MergeRequestsOpen {
+		open[mr.ProjectID]++
+		assignees[mr.ProjectID] += mr.Assignees
+	}
+
+	for projectID, count := range open {
+		ch <- prometheus.MustNewConstMetric(c.projectAvgAssignees, prometheus.GaugeValue, float64(assignees[projectID])/float64(count), projectID)
+	}
+}
+
+//collectProjectOldestUnapproved exports the age of the oldest open MR with approvals left per project, projects without them are left out.
+func collectProjectOldestUnapproved(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	created := map[string]*time.Time{}
+	for _, mr := range *stats.MergeRequestsOpen {
+		created[mr.ID] = mr.CreatedAt
+	}
+
+	oldest := map[string]float64{}
+	for _, approval := range *stats.Approvals {
+		createdAt := created[approval.ID]
+		if approval.Approvals == 0 || createdAt == nil {
+			continue
+		}
+
+		age := time.Since(*createdAt).Round(time.Second).Seconds()
+		if current, ok := oldest[approval.ProjectID]; !ok || age > current {
+			oldest[approval.ProjectID] = age
+		}
+	}
+
+	for projectID, age := range oldest {
+		ch <- prometheus.MustNewConstMetric(c.projectOldestUnapproved, prometheus.GaugeValue, age, projectID)
+	}
+}
+
+func collectProjectTimeInState(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	type average struct {
+		total float64
+		count int
+	}
+
+	averages := map[string]map[string]*average{}
+	for _, mr := range *stats.StateDurations {
+		if _, ok := averages[mr.ProjectID]; !ok {
+			averages[mr.ProjectID] = map[string]*average{}
+		}
+		for state, seconds := range mr.Durations {
+			if _, ok := averages[mr.ProjectID][state]; !ok {
+				averages[mr.ProjectID][state] = &average{}
+			}
+			averages[mr.ProjectID][state].total += seconds
+			averages[mr.ProjectID][state].count++
+		}
+	}
+
+	for projectID, states := range averages {
+		for state, avg := range states {
+			ch <- prometheus.MustNewConstMetric(c.projectTimeInState, prometheus.GaugeValue, avg.total/float64(avg.count), projectID, state)
+		}
+	}
+}
+
+func collectProjectCommits(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	for _, commits := range *stats.CommitCounts {
+		ch <- prometheus.MustNewConstMetric(c.projectCommits, prometheus.GaugeValue, float64(commits.Commits), commits.ProjectID)
+	}
+}
+
+func collectProtectedBranchApprovals(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	for _, branch := range *stats.ProtectedBranches {
+		ch <- prometheus.MustNewConstMetric(c.protectedBranchApprovals, prometheus.GaugeValue, float64(branch.ApprovalsRequired), branch.ProjectID, branch.Branch)
 	}
 }
 
+func collectProjectApprovalRules(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	for _, counts := range *stats.ApprovalRuleCounts {
+		ch <- prometheus.MustNewConstMetric(c.projectApprovalRules, prometheus.GaugeValue, float64(counts.Rules), counts.ProjectID)
+	}
+}
+
+func collectOpenMergeRequestsAge(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	if len(c.openAgeBuckets) == 0 {
+		return
+	}
+	buckets := ageBucketNames(c.openAgeBuckets)
+
+	counts := map[string][]int{}
+	for _, mr := range *stats.MergeRequestsOpen {
+		if _, ok := counts[mr.ProjectID]; !ok {
+			counts[mr.ProjectID] = make([]int, len(buckets))
+		}
+
+		age := time.Since(*mr.CreatedAt)
+		bucket := len(c.openAgeBuckets)
+		for i, bound := range c.openAgeBuckets {
+			if age < bound {
+				bucket = i
+				break
+			}
+		}
+		counts[mr.ProjectID][bucket]++
+	}
+
+	for projectID, projectCounts := range counts {
+		for i, count := range projectCounts {
+			ch <- prometheus.MustNewConstMetric(c.openMergeRequestsAge, prometheus.GaugeValue, float64(count), projectID, buckets[i])
+		}
+	}
+}
+
+//ageBucketNames returns readable names for the buckets between the bounds, e.g. <1d, 1d-3d and >3d.
+func ageBucketNames(bounds []time.Duration) []string {
+	names := make([]string, 0, len(bounds)+1)
+	for i, bound := range bounds {
+		if i == 0 {
+			names = append(names, "<"+formatAge(bound))
+			continue
+		}
+		names = append(names, formatAge(bounds[i-1])+"-"+formatAge(bound))
+	}
+	return append(names, ">"+formatAge(bounds[len(bounds)-1]))
+}
+
+//formatAge formats the duration in days or hours when possible.
+func formatAge(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d%day == 0:
+		return strconv.Itoa(int(d/day)) + "d"
+	case d%time.Hour == 0:
+		return strconv.Itoa(int(d/time.Hour)) + "h"
+	}
+	return d.String()
+}
+
 func collectOpenMergeRequestMetrics(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
 	for _, mr := range *stats.MergeRequestsOpen {
 		changes := 0.0
@@ -128,12 +1058,37 @@ func collectOpenMergeRequestMetrics(c *Collector, ch chan<- prometheus.Metric, s
 		}
 
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestCreated, prometheus.GaugeValue, float64(time.Time(*mr.CreatedAt).Unix()), mr.ID, mr.ProjectID)
-		ch <- prometheus.MustNewConstMetric(c.mergeRequestUpdated, prometheus.GaugeValue, time.Since(*mr.LastUpdated).Round(time.Second).Seconds(), mr.ID, mr.ProjectID)
-		ch <- prometheus.MustNewConstMetric(c.mergeRequestChangedFiles, prometheus.GaugeValue, changes, mr.ID, mr.ProjectID)
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestUpdated, prometheus.GaugeValue, c.staleness(mr), mr.ID, mr.ProjectID)
+		// An empty changes count means Gitlab is still computing the diff, which isn't the same as 0 changed files.
+		if mr.ChangeCount == "" {
+			ch <- prometheus.MustNewConstMetric(c.mergeRequestPending, prometheus.GaugeValue, 1, mr.ID, mr.ProjectID)
+		} else {
+			ch <- prometheus.MustNewConstMetric(c.mergeRequestChangedFiles, prometheus.GaugeValue, changes, mr.ID, mr.ProjectID)
+		}
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestAssignees, prometheus.GaugeValue, float64(mr.Assignees), mr.ID, mr.ProjectID)
+
+		noReviewer := 0.0
+		if mr.Reviewers == 0 {
+			noReviewer = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestNoReviewer, prometheus.GaugeValue, noReviewer, mr.ID, mr.ProjectID)
+
+		rebasing := 0.0
+		if mr.RebaseInProgress {
+			rebasing = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestRebasing, prometheus.GaugeValue, rebasing, mr.ID, mr.ProjectID)
 	}
 }
 
+//staleness returns the seconds since the last update of the MR, or since its creation when that is the staleness basis.
+func (c *Collector) staleness(mr client.MergeRequestStats) float64 {
+	if c.stalenessBasis == "created" {
+		return time.Since(*mr.CreatedAt).Round(time.Second).Seconds()
+	}
+	return time.Since(*mr.LastUpdated).Round(time.Second).Seconds()
+}
+
 func collectClosedMergeRequestMetrics(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
 	for _, mr := range *stats.MergeRequestsClosed {
 		changes := 0.0
@@ -144,7 +1099,7 @@ func collectClosedMergeRequestMetrics(c *Collector, ch chan<- prometheus.Metric,
 		}
 
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestCreated, prometheus.GaugeValue, float64(time.Time(*mr.MergeRequest.CreatedAt).Unix()), mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
-		ch <- prometheus.MustNewConstMetric(c.mergeRequestUpdated, prometheus.GaugeValue, time.Since(*mr.MergeRequest.LastUpdated).Round(time.Second).Seconds(), mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestUpdated, prometheus.GaugeValue, c.staleness(mr.MergeRequest), mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestChangedFiles, prometheus.GaugeValue, changes, mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestClosed, prometheus.GaugeValue, float64(time.Time(*mr.ClosedAt).Unix()), mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestAssignees, prometheus.GaugeValue, float64(mr.MergeRequest.Assignees), mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
@@ -162,17 +1117,100 @@ func collectMergedMergeRequestMetrics(c *Collector, ch chan<- prometheus.Metric,
 		}
 
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestCreated, prometheus.GaugeValue, float64(time.Time(*mr.MergeRequest.CreatedAt).Unix()), mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
-		ch <- prometheus.MustNewConstMetric(c.mergeRequestUpdated, prometheus.GaugeValue, time.Since(*mr.MergeRequest.LastUpdated).Round(time.Second).Seconds(), mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestUpdated, prometheus.GaugeValue, c.staleness(mr.MergeRequest), mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestChangedFiles, prometheus.GaugeValue, changes, mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestMerged, prometheus.GaugeValue, float64(time.Time(*mr.MergedAt).Unix()), mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestAssignees, prometheus.GaugeValue, float64(mr.MergeRequest.Assignees), mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestDuration, prometheus.GaugeValue, mr.Duration, mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
+
+		if c.businessCalendar != nil {
+			business := c.businessCalendar.Duration(*mr.MergeRequest.CreatedAt, *mr.MergedAt)
+			ch <- prometheus.MustNewConstMetric(c.mergeRequestBusiness, prometheus.GaugeValue, business.Seconds(), mr.MergeRequest.ID, mr.MergeRequest.ProjectID)
+		}
 	}
 }
 
 func collectMergeRequestApprovalMetrics(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
 	for _, approval := range *stats.Approvals {
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestApprovals, prometheus.GaugeValue, float64(approval.Approvals), approval.ID, approval.ProjectID)
+
+		for _, rule := range mergeApprovalRules(approval.Rules) {
+			ch <- prometheus.MustNewConstMetric(c.mergeRequestApprovalRules, prometheus.GaugeValue, float64(rule.ApprovalsRequired), approval.ID, approval.ProjectID, rule.Name, rule.Type)
+
+			// Code owner rules for the same pattern in different sections are only satisfied when all of them are.
+			if rule.Type == "code_owner" {
+				satisfied := 0.0
+				if rule.Approved {
+					satisfied = 1
+				}
+				ch <- prometheus.MustNewConstMetric(c.mergeRequestCodeOwnerRule, prometheus.GaugeValue, satisfied, approval.ID, approval.ProjectID, rule.Name)
+			}
+		}
+
+		codeOwnerRules := 0
+		codeOwnerLeft := 0
+		for _, rule := range approval.Rules {
+			if rule.Type == "code_owner" {
+				codeOwnerRules++
+				if left := rule.ApprovalsRequired - rule.ApprovalsGiven; left > 0 {
+					codeOwnerLeft += left
+				}
+			}
+		}
+		if codeOwnerRules > 0 {
+			ch <- prometheus.MustNewConstMetric(c.mergeRequestCodeOwnerLeft, prometheus.GaugeValue, float64(codeOwnerLeft), approval.ID, approval.ProjectID)
+		}
+
+		if approval.AwaitingUser {
+			ch <- prometheus.MustNewConstMetric(c.mergeRequestAwaitingMe, prometheus.GaugeValue, 1, approval.ID, approval.ProjectID)
+		}
+	}
+}
+
+//mergeApprovalRules combines the rules with the same name and type, e.g. code owner rules for the same pattern in different sections.
+//The approvals required and given are summed, the combined rule is approved when all of its rules are.
+func mergeApprovalRules(rules []client.ApprovalRuleStats) []client.ApprovalRuleStats {
+	var result []client.ApprovalRuleStats
+	index := map[[2]string]int{}
+
+	for _, rule := range rules {
+		key := [2]string{rule.Name, rule.Type}
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(result)
+			result = append(result, rule)
+			continue
+		}
+		result[i].ApprovalsRequired += rule.ApprovalsRequired
+		result[i].ApprovalsGiven += rule.ApprovalsGiven
+		result[i].Approved = result[i].Approved && rule.Approved
+	}
+
+	return result
+}
+
+//collectMergeRequestApprovalSLA flags the open MRs with approvals left that were created longer than the SLA ago.
+func collectMergeRequestApprovalSLA(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	if c.approvalSLA <= 0 {
+		return
+	}
+
+	created := map[string]*time.Time{}
+	for _, mr := range *stats.MergeRequestsOpen {
+		created[mr.ID] = mr.CreatedAt
+	}
+
+	for _, approval := range *stats.Approvals {
+		createdAt := created[approval.ID]
+		if createdAt == nil {
+			continue
+		}
+
+		breached := 0.0
+		if approval.Approvals > 0 && time.Since(*createdAt) > c.approvalSLA {
+			breached = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestSLABreached, prometheus.GaugeValue, breached, approval.ID, approval.ProjectID)
 	}
 }
 
@@ -180,5 +1218,214 @@ func collectMergeRequestChanges(c *Collector, ch chan<- prometheus.Metric, stats
 	for _, changes := range *stats.Changes {
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestChanges, prometheus.GaugeValue, float64(changes.Additions), changes.ID, changes.ProjectID, "added")
 		ch <- prometheus.MustNewConstMetric(c.mergeRequestChanges, prometheus.GaugeValue, float64(changes.Deletions), changes.ID, changes.ProjectID, "deleted")
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestDiffBytes, prometheus.GaugeValue, float64(changes.DiffBytes), changes.ID, changes.ProjectID)
+
+		for _, extension := range changes.Extensions {
+			ch <- prometheus.MustNewConstMetric(c.mergeRequestChangesByType, prometheus.GaugeValue, float64(extension.Additions), changes.ID, changes.ProjectID, extension.Extension, "added")
+			ch <- prometheus.MustNewConstMetric(c.mergeRequestChangesByType, prometheus.GaugeValue, float64(extension.Deletions), changes.ID, changes.ProjectID, extension.Extension, "deleted")
+		}
+	}
+}
+
+func collectMergeRequestPipelineRetries(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	for _, retries := range *stats.PipelineRetries {
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestRetries, prometheus.GaugeValue, float64(retries.Retries), retries.ID, retries.ProjectID)
+	}
+}
+
+func collectMergeRequestForcePushes(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	for _, forcePush := range *stats.ForcePushes {
+		forcePushed := 0.0
+		if forcePush.AfterApproval {
+			forcePushed = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestForcePushed, prometheus.GaugeValue, forcePushed, forcePush.ID, forcePush.ProjectID)
+	}
+}
+
+func collectMergeRequestChangesRequested(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	for _, requested := range *stats.ChangesRequested {
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestChangesAsked, prometheus.GaugeValue, float64(requested.Reviewers), requested.ID, requested.ProjectID)
+	}
+}
+
+func collectMergeRequestReviewRounds(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	for _, rounds := range *stats.ReviewRounds {
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestReviewRounds, prometheus.GaugeValue, float64(rounds.Rounds), rounds.ID, rounds.ProjectID)
+	}
+}
+
+func collectMergeRequestDiscussions(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	for _, discussions := range *stats.Discussions {
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestDiscussions, prometheus.GaugeValue, float64(discussions.Author), discussions.ID, discussions.ProjectID, "author")
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestDiscussions, prometheus.GaugeValue, float64(discussions.Reviewer), discussions.ID, discussions.ProjectID, "reviewer")
+	}
+}
+
+func collectMergeRequestLabelEvents(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	for _, event := range *stats.LabelEvents {
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestLabelAdded, prometheus.CounterValue, float64(event.Added), event.ID, event.ProjectID, event.Label)
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestLabelRemoved, prometheus.CounterValue, float64(event.Removed), event.ID, event.ProjectID, event.Label)
+	}
+}
+
+func collectMergeRequestReopened(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	reopened := map[string]int{}
+	for _, reopen := range *stats.Reopens {
+		if _, ok := reopened[reopen.ProjectID]; !ok {
+			reopened[reopen.ProjectID] = 0
+		}
+		if reopen.Reopened {
+			reopened[reopen.ProjectID]++
+		}
+	}
+
+	for projectID, count := range reopened {
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestReopened, prometheus.GaugeValue, float64(count), projectID)
+	}
+}
+
+func collectMergeRequestApprovalBypassed(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	bypassed := map[string]int{}
+	for _, approval := range *stats.MergedApprovals {
+		if _, ok := bypassed[approval.ProjectID]; !ok {
+			bypassed[approval.ProjectID] = 0
+		}
+		if approval.Approvals > 0 {
+			bypassed[approval.ProjectID]++
+		}
+	}
+
+	for projectID, count := range bypassed {
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestApprovalBypassed, prometheus.GaugeValue, float64(count), projectID)
+	}
+}
+
+func collectMergeRequestFailedPipelines(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	type projectStatus struct {
+		projectID string
+		status    string
+	}
+
+	merged := map[projectStatus]int{}
+	for _, mr := range *stats.MergeRequestsMerged {
+		for _, status := range []string{"failed", "skipped"} {
+			key := projectStatus{mr.MergeRequest.ProjectID, status}
+			if _, ok := merged[key]; !ok {
+				merged[key] = 0
+			}
+			if mr.PipelineStatus == status {
+				merged[key]++
+			}
+		}
+	}
+
+	for key, count := range merged {
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestFailedPipeline, prometheus.GaugeValue, float64(count), key.projectID, key.status)
+	}
+}
+
+func collectMergeRequestSelfMerged(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	selfMerged := map[string]int{}
+	for _, mr := range *stats.MergeRequestsMerged {
+		if _, ok := selfMerged[mr.MergeRequest.ProjectID]; !ok {
+			selfMerged[mr.MergeRequest.ProjectID] = 0
+		}
+		// Merges without a known merger (e.g. some merges via the API) can't be attributed.
+		if mr.MergedBy != "" && mr.MergedBy == mr.MergeRequest.Author {
+			selfMerged[mr.MergeRequest.ProjectID]++
+		}
+	}
+
+	for projectID, count := range selfMerged {
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestSelfMerged, prometheus.GaugeValue, float64(count), projectID)
+	}
+}
+
+func collectAuthorMergeRequests(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	if c.public {
+		return
+	}
+
+	opened := map[string]int{}
+	merged := map[string]int{}
+	for _, mr := range *stats.MergeRequests {
+		if mr.Author == "" {
+			continue
+		}
+		opened[mr.Author]++
+		if mr.State == "merged" {
+			merged[mr.Author]++
+		}
+	}
+
+	for username, count := range opened {
+		ch <- prometheus.MustNewConstMetric(c.authorOpenedMergeRequests, prometheus.GaugeValue, float64(count), username)
+		ch <- prometheus.MustNewConstMetric(c.authorMergedMergeRequests, prometheus.GaugeValue, float64(merged[username]), username)
+	}
+}
+
+func collectApproverApprovals(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	if c.public {
+		return
+	}
+
+	approvals := map[string]int{}
+	for _, approval := range *stats.ApproverApprovals {
+		approvals[approval.Username]++
+	}
+
+	for username, count := range approvals {
+		ch <- prometheus.MustNewConstMetric(c.approverApprovals, prometheus.GaugeValue, float64(count), username)
+	}
+}
+
+func collectMergeRequestUpdates(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	for _, updates := range *stats.Updates {
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestUpdates, prometheus.CounterValue, float64(updates.Updates), updates.ID, updates.ProjectID)
+	}
+}
+
+func collectMergeRequestDurationHistogram(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	c.histogramMutex.Lock()
+	defer c.histogramMutex.Unlock()
+
+	// The histogram is filled with the cached stats on every collect.
+	histogram := c.mergeRequestDurationHistogram
+	histogram.Reset()
+
+	for _, mr := range *stats.MergeRequestsMerged {
+		histogram.WithLabelValues("merged").(prometheus.ExemplarObserver).ObserveWithExemplar(mr.Duration, prometheus.Labels{"merge_request_id": mr.MergeRequest.ID, "project_id": mr.MergeRequest.ProjectID})
+	}
+	for _, mr := range *stats.MergeRequestsClosed {
+		histogram.WithLabelValues("closed").(prometheus.ExemplarObserver).ObserveWithExemplar(mr.Duration, prometheus.Labels{"merge_request_id": mr.MergeRequest.ID, "project_id": mr.MergeRequest.ProjectID})
+	}
+
+	histogram.Collect(ch)
+}
+
+//collectMergeRequestLeadTimeHistogram sends the lead time histogram, only partitioned by project when configured.
+func collectMergeRequestLeadTimeHistogram(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	c.histogramMutex.Lock()
+	defer c.histogramMutex.Unlock()
+
+	// The histogram is filled with the cached stats on every collect.
+	histogram := c.mergeRequestLeadTimeHistogram
+	histogram.Reset()
+
+	for _, mr := range *stats.MergeRequestsMerged {
+		var labels []string
+		if len(c.leadTimeLabels) > 0 {
+			labels = []string{mr.MergeRequest.ProjectID}
+		}
+		histogram.WithLabelValues(labels...).(prometheus.ExemplarObserver).ObserveWithExemplar(mr.Duration, prometheus.Labels{"merge_request_id": mr.MergeRequest.ID, "project_id": mr.MergeRequest.ProjectID})
+	}
+
+	histogram.Collect(ch)
+}
+
+func collectMergeRequestPickups(c *Collector, ch chan<- prometheus.Metric, stats *client.Stats) {
+	for _, pickup := range *stats.Pickups {
+		ch <- prometheus.MustNewConstMetric(c.mergeRequestPickup, prometheus.GaugeValue, pickup.Seconds, pickup.ID, pickup.ProjectID)
 	}
 }